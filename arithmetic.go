@@ -18,11 +18,17 @@ import (
 	"go/ast"
 	"go/token"
 	"math"
+	"math/big"
 
 	"github.com/muktihari/expr/internal/conv"
 )
 
 func arithmetic(v, vx, vy *Visitor, binaryExpr *ast.BinaryExpr) {
+	if binaryExpr.Op == token.ADD && (vx.value.Kind() == KindString || vy.value.Kind() == KindString) {
+		concatString(v, vx, vy, binaryExpr)
+		return
+	}
+
 	// numeric guards:
 	if vx.value.Kind() <= numeric_beg || vx.value.Kind() >= numeric_end {
 		v.err = newArithmeticNonNumericError(vx, binaryExpr.X)
@@ -35,10 +41,22 @@ func arithmetic(v, vx, vy *Visitor, binaryExpr *ast.BinaryExpr) {
 
 	switch v.options.numericType {
 	case NumericTypeAuto:
+		if isDecimal(vx.value) || isDecimal(vy.value) {
+			calculateDecimal(v, vx, vy, binaryExpr)
+			return
+		}
+		if isBig(vx.value) || isBig(vy.value) {
+			calculateBig(v, vx, vy, binaryExpr)
+			return
+		}
 		if vx.value.Kind() == KindImag || vy.value.Kind() == KindImag {
 			calculateComplex(v, parseComplex(vx.value), parseComplex(vy.value), binaryExpr.Op, binaryExpr.OpPos)
 			return
 		}
+		if (isUint(vx.value) || isUint(vy.value)) && isInteger(vx.value) && isInteger(vy.value) {
+			calculateMixedUint(v, vx, vy, binaryExpr) // uint64 is never routed through float64.
+			return
+		}
 		calculateFloat(v, parseFloat(vx.value), parseFloat(vy.value), binaryExpr.Op)
 		return
 	case NumericTypeComplex:
@@ -48,7 +66,36 @@ func arithmetic(v, vx, vy *Visitor, binaryExpr *ast.BinaryExpr) {
 		calculateFloat(v, parseFloat(vx.value), parseFloat(vy.value), binaryExpr.Op)
 		return
 	case NumericTypeInt:
-		calculateInt(v, parseInt(vx.value), parseInt(vy.value), vy.pos, binaryExpr.Op)
+		calculateInt(v, parseInt(vx.value), parseInt(vy.value), vy.pos, binaryExpr.Op, binaryExpr.OpPos)
+		return
+	case NumericTypeGo: // follow Go's untyped constant rules: int op int stays int, otherwise promote to the higher kind.
+		switch {
+		case isDecimal(vx.value) || isDecimal(vy.value):
+			calculateDecimal(v, vx, vy, binaryExpr)
+		case isBig(vx.value) || isBig(vy.value):
+			calculateBig(v, vx, vy, binaryExpr)
+		case vx.value.Kind() == KindImag || vy.value.Kind() == KindImag:
+			calculateComplex(v, parseComplex(vx.value), parseComplex(vy.value), binaryExpr.Op, binaryExpr.OpPos)
+		case vx.value.Kind() == KindFloat || vy.value.Kind() == KindFloat:
+			calculateFloat(v, parseFloat(vx.value), parseFloat(vy.value), binaryExpr.Op)
+		case isUint(vx.value) || isUint(vy.value):
+			calculateMixedUint(v, vx, vy, binaryExpr)
+		default:
+			calculateInt(v, vx.value.Int64(), vy.value.Int64(), vy.pos, binaryExpr.Op, binaryExpr.OpPos)
+		}
+		return
+	case NumericTypeBig:
+		if isDecimal(vx.value) || isDecimal(vy.value) {
+			calculateDecimal(v, vx, vy, binaryExpr)
+			return
+		}
+		calculateBig(v, vx, vy, binaryExpr)
+		return
+	case NumericTypeDecimal:
+		calculateDecimal(v, vx, vy, binaryExpr)
+		return
+	case NumericTypeUint:
+		calculateUint(v, parseUint(vx.value), parseUint(vy.value), vy.pos, binaryExpr.Op, binaryExpr.OpPos)
 		return
 	}
 }
@@ -99,16 +146,19 @@ func calculateFloat(v *Visitor, x, y float64, op token.Token) {
 	}
 }
 
-func calculateInt(v *Visitor, x, y int64, yPos int, op token.Token) {
+func calculateInt(v *Visitor, x, y int64, yPos int, op token.Token, opPos token.Pos) {
 	v.value.SetKind(KindInt)
 	switch op {
 	case token.ADD:
-		v.value = int64Value(x + y)
+		r, overflow := addInt64(x, y)
+		setInt64(v, r, overflow, x > 0, op, opPos)
 	case token.SUB:
-		v.value = int64Value(x - y)
+		r, overflow := subInt64(x, y)
+		setInt64(v, r, overflow, x >= 0, op, opPos) // "0 - MinInt64" overflows as well.
 	case token.MUL:
-		v.value = int64Value(x * y)
-	case token.QUO:
+		r, overflow := mulInt64(x, y)
+		setInt64(v, r, overflow, (x > 0) == (y > 0), op, opPos)
+	case token.QUO, token.REM:
 		if y == 0 {
 			if v.options.allowIntegerDividedByZero {
 				v.value = int64Value(0)
@@ -122,8 +172,10 @@ func calculateInt(v *Visitor, x, y int64, yPos int, op token.Token) {
 			}
 			return
 		}
-		v.value = int64Value(x / y)
-	case token.REM:
+		if op == token.QUO {
+			setInt64(v, x/y, x == math.MinInt64 && y == -1, true, op, opPos)
+			return
+		}
 		v.value = int64Value(x % y)
 	}
 }
@@ -134,8 +186,10 @@ func parseComplex(val value) complex128 { // kind must be numeric
 		return val.Complex128()
 	case KindFloat:
 		return complex(val.Float64(), 0)
-	case KindInt:
+	case KindInt, KindRune:
 		return complex(float64(val.Int64()), 0)
+	case KindBigInt, KindBigFloat, KindDecimal, KindUint:
+		return complex(parseFloat(val), 0)
 	}
 	return 0
 }
@@ -146,8 +200,18 @@ func parseFloat(val value) float64 {
 		return real(val.Complex128())
 	case KindFloat:
 		return val.Float64()
-	case KindInt:
+	case KindInt, KindRune:
 		return float64(val.Int64())
+	case KindUint:
+		return float64(val.Uint64())
+	case KindBigInt:
+		f, _ := new(big.Float).SetInt(val.BigInt()).Float64()
+		return f
+	case KindBigFloat:
+		f, _ := val.BigFloat().Float64()
+		return f
+	case KindDecimal:
+		return val.Decimal().Float64()
 	}
 	return 0
 }
@@ -158,8 +222,17 @@ func parseInt(val value) int64 {
 		return int64(real(val.Complex128()))
 	case KindFloat:
 		return int64(val.Float64())
-	case KindInt:
+	case KindInt, KindRune:
 		return val.Int64()
+	case KindUint:
+		return int64(val.Uint64())
+	case KindBigInt:
+		return val.BigInt().Int64()
+	case KindBigFloat:
+		i, _ := val.BigFloat().Int64()
+		return i
+	case KindDecimal:
+		return val.Decimal().round(0, RoundDown).int().Int64()
 	}
 	return 0
 }
@@ -114,6 +114,38 @@ func TestArithmetic(t *testing.T) {
 			op:            token.ADD,
 			expectedValue: float64Value(3),
 		},
+		{
+			name:          "arithmetic numeric go: x int y int",
+			v:             newVisitor(NumericTypeGo),
+			vx:            &Visitor{value: int64Value(7)},
+			vy:            &Visitor{value: int64Value(2)},
+			op:            token.QUO,
+			expectedValue: int64Value(3),
+		},
+		{
+			name:          "arithmetic numeric go: x int y int beyond float64 precision",
+			v:             newVisitor(NumericTypeGo),
+			vx:            &Visitor{value: int64Value(9007199254740993)},
+			vy:            &Visitor{value: int64Value(0)},
+			op:            token.ADD,
+			expectedValue: int64Value(9007199254740993),
+		},
+		{
+			name:          "arithmetic numeric go: x int y float",
+			v:             newVisitor(NumericTypeGo),
+			vx:            &Visitor{value: int64Value(7)},
+			vy:            &Visitor{value: float64Value(2)},
+			op:            token.QUO,
+			expectedValue: float64Value(3.5),
+		},
+		{
+			name:          "arithmetic numeric go: x float y imag",
+			v:             newVisitor(NumericTypeGo),
+			vx:            &Visitor{value: float64Value(7)},
+			vy:            &Visitor{value: complex128Value(2)},
+			op:            token.QUO,
+			expectedValue: complex128Value(3.5),
+		},
 	}
 
 	for i, tc := range tt {
@@ -295,18 +327,18 @@ func TestCalculateInt(t *testing.T) {
 			v:              &Visitor{options: options{numericType: NumericTypeInt, allowIntegerDividedByZero: true}},
 			vx:             &Visitor{value: int64Value(10)},
 			vy:             &Visitor{value: int64Value(0)},
-			ops:            []token.Token{token.QUO},
-			expectedValues: []value{int64Value(0)},
-			expectedErrs:   []error{nil},
+			ops:            []token.Token{token.QUO, token.REM},
+			expectedValues: []value{int64Value(0), int64Value(0)},
+			expectedErrs:   []error{nil, nil},
 		},
 		{
 			name:           "calculate integers allowIntegerDividedByZero == false",
 			v:              &Visitor{options: options{numericType: NumericTypeInt, allowIntegerDividedByZero: false}},
 			vx:             &Visitor{value: int64Value(10)},
 			vy:             &Visitor{value: int64Value(0)},
-			ops:            []token.Token{token.QUO},
-			expectedValues: []value{{}},
-			expectedErrs:   []error{ErrIntegerDividedByZero},
+			ops:            []token.Token{token.QUO, token.REM},
+			expectedValues: []value{{}, {}},
+			expectedErrs:   []error{ErrIntegerDividedByZero, ErrIntegerDividedByZero},
 		},
 		{
 			name:           "calculate floats",
@@ -336,7 +368,7 @@ func TestCalculateInt(t *testing.T) {
 				name := fmt.Sprintf("%v%s%v", tc.vx.value.Any(), op, tc.vy.value.Any())
 				t.Run(name, func(t *testing.T) {
 					be := &ast.BinaryExpr{Op: op}
-					calculateInt(tc.v, parseInt(tc.vx.value), parseInt(tc.vy.value), tc.vy.pos, be.Op)
+					calculateInt(tc.v, parseInt(tc.vx.value), parseInt(tc.vy.value), tc.vy.pos, be.Op, be.OpPos)
 					if !errors.Is(tc.v.err, tc.expectedErrs[i]) {
 						t.Fatalf("expected err: %v, got: %v", tc.expectedErrs[i], tc.v.err)
 					}
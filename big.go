@@ -0,0 +1,298 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"math"
+	"math/big"
+
+	"github.com/muktihari/expr/internal/conv"
+)
+
+// DefaultBigFloatPrecision is the default mantissa precision (in bits) of *big.Float used by NumericTypeBig.
+const DefaultBigFloatPrecision = 256
+
+// maxBigShiftBits limits the length (in bits) of the result of "x << y" on big integers, so a single shift such as
+// "1 << 4000000000" could not allocate hundreds of megabytes.
+const maxBigShiftBits = 1 << 18
+
+// isBig reports whether val is either *big.Int or *big.Float.
+func isBig(val value) bool {
+	k := val.Kind()
+	return k == KindBigInt || k == KindBigFloat
+}
+
+// isNumeric reports whether val's kind is a numeric kind.
+func isNumeric(val value) bool { return val.Kind() > numeric_beg && val.Kind() < numeric_end }
+
+// isInteger reports whether val's kind is an integer kind.
+func isInteger(val value) bool {
+	k := val.Kind()
+	return k == KindInt || k == KindUint || k == KindBigInt || k == KindRune
+}
+
+// bigFloatPrecision returns the precision of *big.Float, 0 means DefaultBigFloatPrecision.
+func (o *options) bigFloatPrecision() uint {
+	if o.bigFloatPrec == 0 {
+		return DefaultBigFloatPrecision
+	}
+	return o.bigFloatPrec
+}
+
+// parseBigInt converts val into *big.Int, it returns false if val is not an integer value.
+func parseBigInt(val value) (*big.Int, bool) {
+	switch val.Kind() {
+	case KindBigInt:
+		return val.BigInt(), true
+	case KindInt, KindRune:
+		return big.NewInt(val.Int64()), true
+	case KindUint:
+		return new(big.Int).SetUint64(val.Uint64()), true
+	case KindFloat:
+		f := val.Float64()
+		if math.IsInf(f, 0) || math.IsNaN(f) || f != math.Trunc(f) {
+			return nil, false
+		}
+		i, _ := big.NewFloat(f).Int(nil)
+		return i, true
+	case KindBigFloat:
+		if !val.BigFloat().IsInt() {
+			return nil, false
+		}
+		i, _ := val.BigFloat().Int(nil)
+		return i, true
+	}
+	return nil, false
+}
+
+// parseBigFloat converts val into *big.Float, it returns false if val is a NaN or a complex number.
+func parseBigFloat(val value, prec uint) (*big.Float, bool) {
+	switch val.Kind() {
+	case KindBigFloat:
+		return val.BigFloat(), true
+	case KindBigInt:
+		return new(big.Float).SetPrec(prec).SetInt(val.BigInt()), true
+	case KindInt, KindRune:
+		return new(big.Float).SetPrec(prec).SetInt64(val.Int64()), true
+	case KindUint:
+		return new(big.Float).SetPrec(prec).SetUint64(val.Uint64()), true
+	case KindFloat:
+		if f := val.Float64(); !math.IsNaN(f) {
+			return new(big.Float).SetPrec(prec).SetFloat64(f), true
+		}
+	}
+	return nil, false
+}
+
+// parseBigFloatLiteral parses lit into *big.Float using prec.
+func parseBigFloatLiteral(lit string, prec uint) (*big.Float, error) {
+	f, _, err := big.ParseFloat(lit, 0, prec, big.ToNearestEven)
+	return f, err
+}
+
+// calculateBig does arithmetic operation on big numbers. Integer with integer stays integer with truncating division,
+// mixing with a float promotes into *big.Float and mixing with a complex number promotes into complex128.
+func calculateBig(v *Visitor, vx, vy *Visitor, binaryExpr *ast.BinaryExpr) {
+	if vx.value.Kind() == KindImag || vy.value.Kind() == KindImag {
+		calculateComplex(v, parseComplex(vx.value), parseComplex(vy.value), binaryExpr.Op, binaryExpr.OpPos)
+		return
+	}
+
+	if isInteger(vx.value) && isInteger(vy.value) {
+		x, _ := parseBigInt(vx.value)
+		y, _ := parseBigInt(vy.value)
+		calculateBigInt(v, x, y, vy.pos, binaryExpr.Op)
+		return
+	}
+
+	prec := v.options.bigFloatPrecision()
+	x, ok := parseBigFloat(vx.value, prec)
+	if !ok {
+		v.err = newBigNaNError(vx, binaryExpr.X)
+		return
+	}
+	y, ok := parseBigFloat(vy.value, prec)
+	if !ok {
+		v.err = newBigNaNError(vy, binaryExpr.Y)
+		return
+	}
+	calculateBigFloat(v, x, y, prec, binaryExpr.Op, binaryExpr.OpPos)
+}
+
+func newBigNaNError(v *Visitor, e ast.Expr) error {
+	return &SyntaxError{
+		Msg: "result of \"" + conv.FormatExpr(e) + "\" is NaN which is not representable as *big.Float",
+		Pos: v.pos,
+		Err: ErrArithmeticOperation,
+	}
+}
+
+func calculateBigInt(v *Visitor, x, y *big.Int, yPos int, op token.Token) {
+	switch op {
+	case token.ADD:
+		v.value = bigIntValue(new(big.Int).Add(x, y))
+	case token.SUB:
+		v.value = bigIntValue(new(big.Int).Sub(x, y))
+	case token.MUL:
+		v.value = bigIntValue(new(big.Int).Mul(x, y))
+	case token.QUO, token.REM:
+		if y.Sign() == 0 {
+			if v.options.allowIntegerDividedByZero {
+				v.value = bigIntValue(new(big.Int))
+				return
+			}
+			v.value = value{}
+			v.err = &SyntaxError{
+				Msg: "could not divide x with zero y, allowIntegerDividedByZero == false",
+				Pos: yPos,
+				Err: ErrIntegerDividedByZero,
+			}
+			return
+		}
+		if op == token.QUO {
+			v.value = bigIntValue(new(big.Int).Quo(x, y)) // truncated like Go
+			return
+		}
+		v.value = bigIntValue(new(big.Int).Rem(x, y))
+	}
+}
+
+func calculateBigFloat(v *Visitor, x, y *big.Float, prec uint, op token.Token, opPos token.Pos) {
+	defer func() { // big.Float panics with big.ErrNaN on operation that results NaN, e.g. Inf - Inf.
+		if r := recover(); r != nil {
+			nan, ok := r.(big.ErrNaN)
+			if !ok {
+				panic(r)
+			}
+			v.value = value{}
+			v.err = &SyntaxError{
+				Msg: "operator \"" + op.String() + "\" results NaN: " + nan.Error(),
+				Pos: int(opPos),
+				Err: ErrArithmeticOperation,
+			}
+		}
+	}()
+
+	z := new(big.Float).SetPrec(prec)
+	switch op {
+	case token.ADD:
+		v.value = bigFloatValue(z.Add(x, y))
+	case token.SUB:
+		v.value = bigFloatValue(z.Sub(x, y))
+	case token.MUL:
+		v.value = bigFloatValue(z.Mul(x, y))
+	case token.QUO:
+		v.value = bigFloatValue(z.Quo(x, y))
+	case token.REM: // x - y * trunc(x / y), the same as math.Mod
+		if y.Sign() == 0 || x.IsInf() {
+			panic(big.ErrNaN{})
+		}
+		if y.IsInf() {
+			v.value = bigFloatValue(z.Set(x))
+			return
+		}
+		q, _ := z.Quo(x, y).Int(nil)
+		qy := new(big.Float).SetPrec(prec).SetInt(q)
+		v.value = bigFloatValue(new(big.Float).SetPrec(prec).Sub(x, qy.Mul(qy, y)))
+	}
+}
+
+// compareBig compares big numbers, mixing with a complex number will be compared as complex128.
+func compareBig(v, vx, vy *Visitor, binaryExpr *ast.BinaryExpr) {
+	if vx.value.Kind() == KindImag || vy.value.Kind() == KindImag {
+		compareComplex(v, parseComplex(vx.value), parseComplex(vy.value), binaryExpr.Op, binaryExpr.OpPos)
+		return
+	}
+
+	var cmp int
+	if isInteger(vx.value) && isInteger(vy.value) {
+		x, _ := parseBigInt(vx.value)
+		y, _ := parseBigInt(vy.value)
+		cmp = x.Cmp(y)
+	} else {
+		prec := v.options.bigFloatPrecision()
+		x, xok := parseBigFloat(vx.value, prec)
+		y, yok := parseBigFloat(vy.value, prec)
+		if !xok || !yok { // NaN
+			v.value = boolValue(binaryExpr.Op == token.NEQ)
+			return
+		}
+		cmp = x.Cmp(y)
+	}
+
+	switch binaryExpr.Op {
+	case token.EQL:
+		v.value = boolValue(cmp == 0)
+	case token.NEQ:
+		v.value = boolValue(cmp != 0)
+	case token.GTR:
+		v.value = boolValue(cmp > 0)
+	case token.GEQ:
+		v.value = boolValue(cmp >= 0)
+	case token.LSS:
+		v.value = boolValue(cmp < 0)
+	case token.LEQ:
+		v.value = boolValue(cmp <= 0)
+	}
+}
+
+// bitwiseBig does bitwise operation on big integers, float without decimal is treated as an integer.
+func bitwiseBig(v, vx, vy *Visitor, binaryExpr *ast.BinaryExpr) {
+	x, ok := parseBigInt(vx.value)
+	if !ok {
+		v.err = newBitwiseNonIntegerError(vx, binaryExpr.X)
+		return
+	}
+	y, ok := parseBigInt(vy.value)
+	if !ok {
+		v.err = newBitwiseNonIntegerError(vy, binaryExpr.Y)
+		return
+	}
+
+	switch binaryExpr.Op {
+	case token.AND:
+		v.value = bigIntValue(new(big.Int).And(x, y))
+	case token.OR:
+		v.value = bigIntValue(new(big.Int).Or(x, y))
+	case token.XOR:
+		v.value = bigIntValue(new(big.Int).Xor(x, y))
+	case token.AND_NOT:
+		v.value = bigIntValue(new(big.Int).AndNot(x, y))
+	case token.SHL, token.SHR:
+		if y.Sign() < 0 || !y.IsUint64() || y.Uint64() > math.MaxUint32 {
+			v.err = &SyntaxError{
+				Msg: "invalid shift count \"" + y.String() + "\"",
+				Pos: vy.pos,
+				Err: ErrBitwiseOperation,
+			}
+			return
+		}
+		if binaryExpr.Op == token.SHL {
+			if x.Sign() != 0 && uint64(x.BitLen())+y.Uint64() > maxBigShiftBits {
+				v.err = &SyntaxError{
+					Msg: fmt.Sprintf("shift count \"%s\" is too large: the result exceeds %d bits", y, maxBigShiftBits),
+					Pos: vy.pos,
+					Err: ErrBitwiseOperation,
+				}
+				return
+			}
+			v.value = bigIntValue(new(big.Int).Lsh(x, uint(y.Uint64())))
+			return
+		}
+		v.value = bigIntValue(new(big.Int).Rsh(x, uint(y.Uint64())))
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"math/big"
+	"strconv"
+	"testing"
+)
+
+func TestNumericTypeBig(t *testing.T) {
+	tt := []struct {
+		in           string
+		options      []Option
+		expectedKind Kind
+		expectedStr  string
+		expectedErr  error
+	}{
+		{in: "99999999999999999999 + 1", expectedKind: KindBigInt, expectedStr: "100000000000000000000"},
+		{in: "340282366920938463463374607431768211455 - 1", expectedKind: KindBigInt, expectedStr: "340282366920938463463374607431768211454"},
+		{in: "18446744073709551616 * 18446744073709551616", expectedKind: KindBigInt, expectedStr: "340282366920938463463374607431768211456"},
+		{in: "-7 / 2", expectedKind: KindBigInt, expectedStr: "-3"},
+		{in: "-7 % 2", expectedKind: KindBigInt, expectedStr: "-1"},
+		{in: "0x10 + 0b11 + 0o7", expectedKind: KindBigInt, expectedStr: "26"},
+		{in: "-(1 << 100)", expectedKind: KindBigInt, expectedStr: "-1267650600228229401496703205376"},
+		{in: "7 / 0", expectedKind: KindBigInt, expectedStr: "0"},
+		{in: "7 / 0", options: []Option{WithAllowIntegerDividedByZero(false)}, expectedErr: ErrIntegerDividedByZero},
+		{in: "7 % 0", options: []Option{WithAllowIntegerDividedByZero(false)}, expectedErr: ErrIntegerDividedByZero},
+		{in: "7 / 2.0", expectedKind: KindBigFloat, expectedStr: "3.5"},
+		{in: "0.1 + 0.2 == 0.3", expectedKind: KindBoolean, expectedStr: "true"},
+		{in: "1.0 / 3", options: []Option{WithBigFloatPrecision(24)}, expectedKind: KindBigFloat, expectedStr: "0.33333334"},
+		{in: "7.5 % 2", expectedKind: KindBigFloat, expectedStr: "1.5"},
+		{in: "-7.5 % 2", expectedKind: KindBigFloat, expectedStr: "-1.5"},
+		{in: "7.5 % 0", expectedErr: ErrArithmeticOperation},
+		{in: "1.0 / 0", expectedKind: KindBigFloat, expectedStr: "+Inf"},
+		{in: "1.0 / 0 - 1.0 / 0", expectedErr: ErrArithmeticOperation},
+		{in: "2 * (1+2i)", expectedKind: KindImag, expectedStr: "(2+4i)"},
+		{in: "99999999999999999999 > 99999999999999999998", expectedKind: KindBoolean, expectedStr: "true"},
+		{in: "99999999999999999999 == 99999999999999999999.0", expectedKind: KindBoolean, expectedStr: "true"},
+		{in: "99999999999999999999 <= 1.5", expectedKind: KindBoolean, expectedStr: "false"},
+		{in: "1 == 1+0i", expectedKind: KindBoolean, expectedStr: "true"},
+		{in: "1 << 128 >> 127", expectedKind: KindBigInt, expectedStr: "2"},
+		{in: "(1 << 128 - 1) & 0xff", expectedKind: KindBigInt, expectedStr: "255"},
+		{in: "0xf0 | 0x0f ^ 0xff", expectedKind: KindBigInt, expectedStr: "0"},
+		{in: "0xff &^ 0x0f", expectedKind: KindBigInt, expectedStr: "240"},
+		{in: "4.0 << 2", expectedKind: KindBigInt, expectedStr: "16"},
+		{in: "4.5 << 2", expectedErr: ErrBitwiseOperation},
+		{in: "1 << -1", expectedErr: ErrBitwiseOperation},
+		{in: "1 << 4294967296", expectedErr: ErrBitwiseOperation},
+		{in: "1 << 4000000000", expectedErr: ErrBitwiseOperation},
+		{in: "1 << 262143 >> 262143", expectedKind: KindBigInt, expectedStr: "1"},
+		{in: "1 << 262144", expectedErr: ErrBitwiseOperation},
+		{in: "(1 << 200000) << 100000", expectedErr: ErrBitwiseOperation},
+		{in: "0 << 4000000000", expectedKind: KindBigInt, expectedStr: "0"},
+		{in: "1 << 4000000000 >> 4000000000", expectedErr: ErrBitwiseOperation},
+		{in: "1 + true", expectedErr: ErrArithmeticOperation},
+	}
+
+	for i, tc := range tt {
+		tc := tc
+		t.Run(fmt.Sprintf("[%d] %s", i, tc.in), func(t *testing.T) {
+			e, err := parser.ParseExpr(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := NewVisitor(append([]Option{WithNumericType(NumericTypeBig)}, tc.options...)...)
+			ast.Walk(v, e)
+
+			if err := v.Err(); !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected err: %v, got: %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr != nil {
+				return
+			}
+			if v.Kind() != tc.expectedKind {
+				t.Fatalf("expected kind: %s, got: %s", tc.expectedKind, v.Kind())
+			}
+			if str := fmt.Sprint(v.ValueAny()); str != tc.expectedStr {
+				t.Fatalf("expected val: %s, got: %s", tc.expectedStr, str)
+			}
+		})
+	}
+}
+
+func TestBigOperandInOtherNumericTypes(t *testing.T) {
+	x, _ := new(big.Int).SetString("99999999999999999999", 10)
+	env := map[string]interface{}{
+		"x": x,
+		"f": big.NewFloat(1.5),
+		"r": big.NewRat(1, 4),
+	}
+
+	tt := []struct {
+		in           string
+		numericType  NumericType
+		expectedKind Kind
+		expectedStr  string
+		expectedErr  error
+	}{
+		{in: "x + 1", expectedKind: KindBigInt, expectedStr: "100000000000000000000"},
+		{in: "x + 1", numericType: NumericTypeGo, expectedKind: KindBigInt, expectedStr: "100000000000000000000"},
+		{in: "x + 0.5", expectedKind: KindBigFloat, expectedStr: "9.99999999999999999995e+19"},
+		{in: "f * 2", expectedKind: KindBigFloat, expectedStr: "3"},
+		{in: "r + f", expectedKind: KindBigFloat, expectedStr: "1.75"},
+		{in: "x > 1", expectedKind: KindBoolean, expectedStr: "true"},
+		{in: "x & 1", expectedKind: KindBigInt, expectedStr: "1"},
+		{in: "-x", expectedKind: KindBigInt, expectedStr: "-99999999999999999999"},
+		{in: "-f", expectedKind: KindBigFloat, expectedStr: "-1.5"},
+		{in: "f + 1", numericType: NumericTypeFloat, expectedKind: KindFloat, expectedStr: "2.5"},
+		{in: "f + 1", numericType: NumericTypeInt, expectedKind: KindInt, expectedStr: "2"},
+		{in: "f + 1", numericType: NumericTypeComplex, expectedKind: KindImag, expectedStr: "(2.5+0i)"},
+		{in: "99999999999999999999", expectedErr: strconv.ErrRange},
+	}
+
+	for i, tc := range tt {
+		tc := tc
+		t.Run(fmt.Sprintf("[%d] %s", i, tc.in), func(t *testing.T) {
+			e, err := parser.ParseExpr(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := NewVisitor(WithNumericType(tc.numericType), WithEnv(env))
+			ast.Walk(v, e)
+
+			if err := v.Err(); !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected err: %v, got: %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr != nil {
+				return
+			}
+			if v.Kind() != tc.expectedKind {
+				t.Fatalf("expected kind: %s, got: %s", tc.expectedKind, v.Kind())
+			}
+			if str := fmt.Sprint(v.ValueAny()); str != tc.expectedStr {
+				t.Fatalf("expected val: %s, got: %s", tc.expectedStr, str)
+			}
+		})
+	}
+}
+
+func TestBigWithMath(t *testing.T) {
+	tt := []struct {
+		in          string
+		expectedStr string
+		expectedErr error
+	}{
+		{in: "abs(-99999999999999999999)", expectedStr: "99999999999999999999"},
+		{in: "abs(-1.5)", expectedStr: "1.5"},
+		{in: "max(99999999999999999999, 99999999999999999998, 1.5)", expectedStr: "99999999999999999999"},
+		{in: "min(99999999999999999999, 2.5)", expectedStr: "2.5"},
+		{in: "floor(-2.5)", expectedStr: "-3"},
+		{in: "ceil(-2.5)", expectedStr: "-2"},
+		{in: "ceil(0.5)", expectedStr: "1"},
+		{in: "floor(-0.5)", expectedStr: "-1"},
+		{in: "trunc(-0.5)", expectedStr: "0"},
+		{in: "trunc(99999999999999999999.5)", expectedStr: "9.9999999999999999999e+19"},
+		{in: "floor(99999999999999999999)", expectedStr: "99999999999999999999"},
+		{in: "round(2.5)", expectedStr: "3"},
+		{in: "round(-2.5)", expectedStr: "-3"},
+		{in: "round(3.14159, 2)", expectedStr: "3.14"},
+		{in: "round(1234.5, -2)", expectedStr: "1200"},
+		{in: "round(1.5, 400)", expectedStr: "1.5"},
+		{in: "round(1.5, -400)", expectedStr: "0"},
+		{in: "round(99999999999999999950, -2)", expectedStr: "100000000000000000000"},
+		{in: "round(-1250, -2)", expectedStr: "-1300"},
+		{in: "round(1250, -400)", expectedStr: "0"},
+		{in: "sqrt(16)", expectedStr: "4"},
+		{in: "pow(2, 10) + 1", expectedStr: "1025"},
+	}
+
+	for i, tc := range tt {
+		tc := tc
+		t.Run(fmt.Sprintf("[%d] %s", i, tc.in), func(t *testing.T) {
+			e, err := parser.ParseExpr(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := NewVisitor(WithNumericType(NumericTypeBig), WithMath())
+			ast.Walk(v, e)
+
+			if err := v.Err(); !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected err: %v, got: %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr != nil {
+				return
+			}
+			if str := fmt.Sprint(v.ValueAny()); str != tc.expectedStr {
+				t.Fatalf("expected val: %s, got: %s", tc.expectedStr, str)
+			}
+		})
+	}
+}
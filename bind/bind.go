@@ -92,7 +92,7 @@ func DefaultIdent() *Ident {
 //   - 100 -> "100"
 //   - 2.1 -> "2.1"
 //   - struct{}{} -> "{}"
-//   - nil -> "<nil>"
+//   - nil -> nil
 type Formatter func(v interface{}) string
 
 // DefaultFormater returns format
@@ -227,6 +227,8 @@ func (b *Binder) Bind(s string, keyvals ...interface{}) (string, error) {
 func Format(v interface{}) string {
 	// declared common used types for faster conversion
 	switch val := v.(type) {
+	case nil:
+		return "nil" // expr's nil, e.g. "{coupon} == nil"
 	case int:
 		return strconv.Itoa(val)
 	case int64:
@@ -243,7 +245,7 @@ func Format(v interface{}) string {
 		return strconv.Quote(val.Error())
 	case fmt.Stringer:
 		return strconv.Quote(val.String())
-	default: // slower but it can handle "{}" "[1, 2]" "<nil>", etc.
+	default: // slower but it can handle "{}" "[1, 2]" "&{}", etc.
 		s := fmt.Sprintf("%v", v) // e.g. int32(2) -> 2
 		if idx := strings.IndexFunc(s, func(r rune) bool {
 			return r == '[' || r == ']' || r == '{' || r == '}' || r == '<' || r == '>'
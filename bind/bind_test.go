@@ -69,7 +69,7 @@ func TestStdBinder(t *testing.T) {
 			keyvals: []interface{}{
 				"is-eligible", nil,
 			},
-			out: "\"<nil>\" == \"<nil>\"",
+			out: "nil == \"<nil>\"",
 		},
 		{
 			in: "{is-eligible} && {age } >= 60",
@@ -209,7 +209,7 @@ func TestFormat(t *testing.T) {
 		{in: time.Time{}, out: "\"0001-01-01 00:00:00 +0000 UTC\""}, // test fmt.Stringer
 		{in: "expr", out: "\"expr\""},
 		{in: struct{}{}, out: "\"{}\""},
-		{in: emptyErr, out: "\"<nil>\""},
+		{in: emptyErr, out: "nil"},
 		{in: fmt.Errorf("error something"), out: "\"error something\""},
 		{in: []byte("c"), out: "\"[99]\""},
 		{in: []string{"abc", "def"}, out: "\"[abc def]\""},
@@ -17,6 +17,8 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"math"
+	"strconv"
 
 	"github.com/muktihari/expr/internal/conv"
 )
@@ -32,6 +34,24 @@ func bitwise(v, vx, vy *Visitor, binaryExpr *ast.BinaryExpr) {
 		return
 	}
 
+	switch numericType := v.options.numericType; numericType {
+	case NumericTypeAuto, NumericTypeGo, NumericTypeBig, NumericTypeDecimal:
+		switch {
+		case numericType == NumericTypeDecimal || isDecimal(vx.value) || isDecimal(vy.value):
+			bitwiseDecimal(v, vx, vy, binaryExpr)
+			return
+		case numericType == NumericTypeBig || isBig(vx.value) || isBig(vy.value):
+			bitwiseBig(v, vx, vy, binaryExpr)
+			return
+		case isUint(vx.value) || (isUint(vy.value) && !isShift(binaryExpr.Op)): // shift's result follows x
+			bitwiseUint(v, vx, vy, binaryExpr)
+			return
+		}
+	case NumericTypeUint:
+		bitwiseUint(v, vx, vy, binaryExpr)
+		return
+	}
+
 	var x, y int64
 	switch v.options.numericType {
 	case NumericTypeFloat:
@@ -43,7 +63,7 @@ func bitwise(v, vx, vy *Visitor, binaryExpr *ast.BinaryExpr) {
 	case NumericTypeInt:
 		x = parseInt(vx.value)
 		y = parseInt(vy.value)
-	case NumericTypeAuto:
+	case NumericTypeAuto, NumericTypeGo:
 		var ok bool
 		x, ok = convertToInt64(vx.value)
 		if !ok {
@@ -67,10 +87,22 @@ func bitwise(v, vx, vy *Visitor, binaryExpr *ast.BinaryExpr) {
 		v.value = int64Value(x ^ y)
 	case token.AND_NOT:
 		v.value = int64Value(x &^ y)
-	case token.SHL:
-		v.value = int64Value(x << y)
-	case token.SHR:
-		v.value = int64Value(x >> y)
+	case token.SHL, token.SHR:
+		if y < 0 {
+			v.value = value{}
+			v.err = &SyntaxError{
+				Msg: "invalid shift count \"" + strconv.FormatInt(y, 10) + "\"",
+				Pos: vy.pos,
+				Err: ErrBitwiseOperation,
+			}
+			return
+		}
+		if binaryExpr.Op == token.SHL {
+			r, overflow := shlInt64(x, y)
+			setInt64(v, r, overflow, x > 0, binaryExpr.Op, binaryExpr.OpPos)
+			return
+		}
+		v.value = int64Value(x >> uint64(y))
 	}
 }
 
@@ -92,6 +124,12 @@ func convertToInt64(val value) (int64, bool) {
 		}
 	case KindInt:
 		return val.Int64(), true
+	case KindUint:
+		if u := val.Uint64(); u <= math.MaxInt64 {
+			return int64(u), true
+		}
 	}
 	return 0, false
 }
+
+func isShift(op token.Token) bool { return op == token.SHL || op == token.SHR }
@@ -0,0 +1,515 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"errors"
+	"go/ast"
+	"go/token"
+	"math/big"
+	"strings"
+
+	"github.com/muktihari/expr/internal/conv"
+)
+
+// Schema declares the Kind of variables for Check, use KindAny if the variable's Kind is only known on evaluation.
+type Schema map[string]Kind
+
+// TypedExpr is a sub-expression and its inferred Kind.
+type TypedExpr struct {
+	Expr string // Formatted sub-expression, e.g. "price * qty".
+	Pos  int    // Position of the sub-expression in expr string, starting from 1.
+	Kind Kind   // Inferred Kind, KindAny if it is only known on evaluation, KindIllegal if it has type error.
+}
+
+// CheckResult is the result of Check.
+type CheckResult struct {
+	Kind  Kind        // Inferred Kind of the expression.
+	Exprs []TypedExpr // Every sub-expression in evaluation order, the expression itself is the last.
+}
+
+// CheckError contains every type error found by Check, each of them is a *SyntaxError.
+type CheckError struct {
+	Errs []error
+}
+
+func (e *CheckError) Error() string {
+	msgs := make([]string, len(e.Errs))
+	for i := range e.Errs {
+		msgs[i] = e.Errs[i].Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether one of the errors matches target.
+func (e *CheckError) Is(target error) bool {
+	for i := range e.Errs {
+		if errors.Is(e.Errs[i], target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap returns the first error.
+func (e *CheckError) Unwrap() error { return e.Errs[0] }
+
+// Check infers the result Kind of every sub-expression of expr string without evaluating the variables or calling
+// the functions, the variables' Kind are declared in schema. It reports every type error as *CheckError, e.g.
+// "a" + 1, !5, true < false and % on complex numbers, using the same rules as the evaluation. Errors that depend on
+// the variables' value such as index out of range or integer overflow are only reported on evaluation. e.g:
+//   - Check("qty > 100 && tier == \"gold\"", Schema{"qty": KindInt, "tier": KindString}) -> KindBoolean
+//   - Check("qty + \"a\"", Schema{"qty": KindInt}) -> error wrapping ErrArithmeticOperation
+//
+// The options are the same as the ones used to evaluate, so the inferred Kind follows the NumericType. It is KindAny if
+// the Kind depends on the variables' value, e.g. "x - 2" where x is KindUint is uint64 unless x < 2. Error messages
+// show a sample value of the variable's Kind, e.g. 1 for KindInt, since the actual value is not known.
+func Check(str string, schema Schema, opts ...Option) (CheckResult, error) {
+	o := defaultOptions()
+	for i := range opts {
+		opts[i](&o)
+	}
+
+	if err := checkInputLen(len(str), o.maxInputLen, 0); err != nil {
+		return CheckResult{}, err
+	}
+
+	e, err := parseExpr(str, &o)
+	if err != nil {
+		return CheckResult{}, err
+	}
+
+	c := &checker{options: o, schema: schema}
+	res := c.check(e)
+	if len(c.errs) > 0 {
+		return CheckResult{Kind: KindIllegal, Exprs: c.exprs}, &CheckError{Errs: c.errs}
+	}
+	return CheckResult{Kind: res.kind, Exprs: c.exprs}, nil
+}
+
+// checker infers the Kind of every sub-expression using the same operations as Visitor, the operands are the actual
+// values if they are constant, otherwise a sample of their Kind.
+type checker struct {
+	options options
+	schema  Schema
+	exprs   []TypedExpr
+	errs    []error
+}
+
+// checked is a checked sub-expression.
+type checked struct {
+	kind     Kind  // KindAny if it is only known on evaluation, KindIllegal if it has type error.
+	value    value // the actual value if it is constant, otherwise sample of kind.
+	constant bool  // true if it does not depend on variables and functions.
+}
+
+var illegal = checked{kind: KindIllegal}
+
+// sample returns a value of the given kind. The numbers are 1 so they will never cause division by zero.
+func (c *checker) sample(kind Kind) (value, bool) {
+	switch kind {
+	case KindBoolean:
+		return boolValue(true), true
+	case KindInt:
+		return int64Value(1), true
+	case KindFloat:
+		return float64Value(1), true
+	case KindImag:
+		return complex128Value(1), true
+	case KindBigInt:
+		return bigIntValue(big.NewInt(1)), true
+	case KindBigFloat:
+		return bigFloatValue(new(big.Float).SetPrec(c.options.bigFloatPrecision()).SetInt64(1)), true
+	case KindDecimal:
+		return decimalValue(NewDecimal(1, 0)), true
+	case KindUint:
+		return uint64Value(1), true
+	case KindRune:
+		return runeValue(1), true
+	case KindString:
+		return stringValue(""), true
+	case KindNil:
+		return nilValue(), true
+	case KindObject:
+		return objectValue(map[string]interface{}{}), true
+	case KindList:
+		return listValue(nil), true
+	}
+	return value{}, false
+}
+
+// variable creates non-constant checked of the given kind.
+func (c *checker) variable(kind Kind) checked {
+	val, ok := c.sample(kind)
+	if !ok {
+		return checked{kind: KindAny}
+	}
+	return checked{kind: kind, value: val}
+}
+
+func (c *checker) report(err error) checked {
+	c.errs = append(c.errs, err)
+	return illegal
+}
+
+func (c *checker) check(e ast.Expr) checked {
+	res := c.checkExpr(e)
+	c.exprs = append(c.exprs, TypedExpr{Expr: conv.FormatExpr(e), Pos: int(e.Pos()), Kind: res.kind})
+	return res
+}
+
+func (c *checker) checkExpr(e ast.Expr) checked {
+	switch d := e.(type) {
+	case *ast.ParenExpr:
+		return c.check(d.X)
+	case *ast.BasicLit:
+		return c.eval(d)
+	case *ast.Ident:
+		if kind, ok := c.schema[d.Name]; ok {
+			return c.variable(kind)
+		}
+		return c.eval(d)
+	case *ast.UnaryExpr:
+		return c.checkUnary(d)
+	case *ast.BinaryExpr:
+		return c.checkBinary(d)
+	case *ast.CallExpr:
+		return c.checkCall(d)
+	case *ast.SelectorExpr:
+		return c.checkSelector(d)
+	case *ast.IndexExpr:
+		return c.checkIndex(d)
+	case *ast.SliceExpr:
+		return c.checkSlice(d)
+	case *ast.CompositeLit:
+		return c.checkCompositeLit(d)
+	}
+	return c.report(newUnsupportedSyntaxError(e))
+}
+
+// eval evaluates constant e.
+func (c *checker) eval(e ast.Expr) checked {
+	v := pool.Get().(*Visitor)
+	defer pool.Put(v)
+	v.reset(c.options)
+
+	v.Visit(e)
+	if v.err != nil {
+		return c.report(v.err)
+	}
+	return checked{kind: v.value.Kind(), value: v.value, constant: true}
+}
+
+// visitor creates Visitor holding res that is checked from e, as if it is evaluated by Visitor.
+func (c *checker) visitor(res checked, e ast.Expr) *Visitor {
+	v := pool.Get().(*Visitor)
+	v.reset(c.options)
+	v.value = res.value
+	if e != nil {
+		v.pos = int(e.Pos())
+	}
+	return v
+}
+
+// result converts the result of an operation evaluated by v into checked.
+func (c *checker) result(v *Visitor, constant bool) checked {
+	if v.err != nil {
+		if !constant && errors.Is(v.err, ErrIntegerOverflow) { // depends on the variables' value.
+			return checked{kind: KindAny}
+		}
+		return c.report(v.err)
+	}
+	if constant {
+		return checked{kind: v.value.Kind(), value: v.value, constant: true}
+	}
+	return c.variable(v.value.Kind())
+}
+
+func (c *checker) checkUnary(unaryExpr *ast.UnaryExpr) checked {
+	switch unaryExpr.Op {
+	case token.NOT, token.ADD, token.SUB:
+	default:
+		return c.report(&SyntaxError{
+			Msg: "operator \"" + unaryExpr.Op.String() + "\" is unsupported",
+			Pos: int(unaryExpr.OpPos),
+			Err: ErrUnsupportedOperator,
+		})
+	}
+
+	x := c.check(unaryExpr.X)
+	switch x.kind {
+	case KindIllegal:
+		return illegal
+	case KindAny:
+		if unaryExpr.Op == token.NOT {
+			return c.variable(KindBoolean)
+		}
+		return x
+	}
+
+	v, vx := c.visitor(checked{}, unaryExpr), c.visitor(x, unaryExpr.X)
+	defer pool.Put(v)
+	defer pool.Put(vx)
+
+	unary(v, vx, unaryExpr)
+	if !x.constant && v.err == nil && x.kind == KindUint && unaryExpr.Op == token.SUB &&
+		c.options.numericType != NumericTypeUint { // -0 is uint64 while the others are int64, see setMixedInt.
+		return checked{kind: KindAny}
+	}
+	return c.result(v, x.constant)
+}
+
+func (c *checker) checkBinary(binaryExpr *ast.BinaryExpr) checked {
+	x, y := c.check(binaryExpr.X), c.check(binaryExpr.Y)
+	if x.kind == KindIllegal || y.kind == KindIllegal {
+		return illegal
+	}
+
+	v, vx, vy := c.visitor(checked{}, binaryExpr), c.visitor(x, binaryExpr.X), c.visitor(y, binaryExpr.Y)
+	defer pool.Put(v)
+	defer pool.Put(vx)
+	defer pool.Put(vy)
+
+	if x.kind == KindAny || y.kind == KindAny {
+		switch binaryExpr.Op {
+		case token.LAND, token.LOR:
+			if x.kind != KindAny && x.kind != KindBoolean {
+				return c.report(newLogicalNonBooleanError(vx, binaryExpr.X))
+			}
+			if y.kind != KindAny && y.kind != KindBoolean {
+				return c.report(newLogicalNonBooleanError(vy, binaryExpr.Y))
+			}
+			return c.variable(KindBoolean)
+		case token.EQL, token.NEQ, token.GTR, token.GEQ, token.LSS, token.LEQ:
+			return c.variable(KindBoolean)
+		}
+		return checked{kind: KindAny}
+	}
+
+	binary(v, vx, vy, binaryExpr) // both X and Y are checked even if it will be short-circuited on evaluation.
+	if !(x.constant && y.constant) && v.err == nil && c.mixedInt(x, y, binaryExpr.Op) {
+		return checked{kind: KindAny}
+	}
+	return c.result(v, x.constant && y.constant)
+}
+
+// mixedInt reports whether the operation mixes uint64 with int64 whose result is uint64 if it is not negative,
+// otherwise int64, see setMixedInt. So the Kind depends on the variables' value, e.g. "x - 2" where x is KindUint.
+func (c *checker) mixedInt(x, y checked, op token.Token) bool {
+	switch c.options.numericType {
+	case NumericTypeAuto, NumericTypeGo:
+	default:
+		return false
+	}
+	switch op {
+	case token.ADD, token.SUB, token.MUL, token.QUO, token.REM:
+	default:
+		return false
+	}
+	if x.kind == KindUint {
+		return y.kind == KindInt || y.kind == KindRune
+	}
+	if y.kind == KindUint {
+		return x.kind == KindInt || x.kind == KindRune
+	}
+	return false
+}
+
+func (c *checker) checkCall(callExpr *ast.CallExpr) checked {
+	if b, ok := lookupBuiltin(callExpr, &c.options); ok {
+		if err := validateArgs(callExpr, conv.FormatExpr(callExpr.Fun), b.minArgs, b.maxArgs); err != nil {
+			return c.report(err)
+		}
+		args, constant, ok := c.checkArgs(callExpr)
+		if !ok {
+			return illegal
+		}
+		if constant {
+			return c.eval(callExpr)
+		}
+		return b.check(c, callExpr, args)
+	}
+
+	fn, err := lookupFunc(callExpr, &c.options)
+	if err != nil {
+		return c.report(err)
+	}
+	args, _, ok := c.checkArgs(callExpr)
+	if !ok {
+		return illegal
+	}
+
+	res := c.variable(fn.Return)
+	for i := range args {
+		if args[i].kind == KindAny {
+			continue
+		}
+		kind := argKind(fn, i)
+		if _, ok := convertArg(args[i].value, kind, c.options.numericType); !ok {
+			res = c.report(newArgumentError(callExpr, i, args[i].value, kind.String()))
+		}
+	}
+	return res
+}
+
+// checkArgs checks callExpr's arguments, constant is true if all of them are constant.
+func (c *checker) checkArgs(callExpr *ast.CallExpr) (args []checked, constant, ok bool) {
+	args = make([]checked, len(callExpr.Args))
+	constant, ok = true, true
+	for i := range callExpr.Args {
+		args[i] = c.check(callExpr.Args[i])
+		constant = constant && args[i].constant
+		ok = ok && args[i].kind != KindIllegal
+	}
+	return args, constant, ok
+}
+
+func (c *checker) checkSelector(selectorExpr *ast.SelectorExpr) checked {
+	x := c.check(selectorExpr.X)
+	switch x.kind {
+	case KindIllegal:
+		return illegal
+	case KindAny, KindObject, KindNil:
+		if x.constant {
+			return c.eval(selectorExpr)
+		}
+		return checked{kind: KindAny}
+	}
+
+	return c.report(newNoFieldsError(selectorExpr, x.value))
+}
+
+func (c *checker) checkIndex(indexExpr *ast.IndexExpr) checked {
+	x, i := c.check(indexExpr.X), c.check(indexExpr.Index)
+	if x.kind == KindIllegal || i.kind == KindIllegal {
+		return illegal
+	}
+	if x.constant && i.constant {
+		return c.eval(indexExpr)
+	}
+
+	switch x.kind {
+	case KindString:
+		if !c.checkIndexValue(i, indexExpr.Index) {
+			return illegal
+		}
+		return c.variable(KindUint)
+	case KindList:
+		if !c.checkIndexValue(i, indexExpr.Index) {
+			return illegal
+		}
+		return checked{kind: KindAny}
+	case KindAny, KindObject, KindNil:
+		return checked{kind: KindAny}
+	}
+
+	vx := c.visitor(x, indexExpr.X)
+	defer pool.Put(vx)
+	return c.report(newNonIndexableError(vx, indexExpr.X))
+}
+
+func (c *checker) checkSlice(sliceExpr *ast.SliceExpr) checked {
+	res := []checked{c.check(sliceExpr.X)}
+	indexes := []ast.Expr{sliceExpr.Low, sliceExpr.High, sliceExpr.Max}
+	constant := res[0].constant
+	for _, e := range indexes {
+		if e == nil {
+			continue
+		}
+		i := c.check(e)
+		if i.kind == KindIllegal {
+			return illegal
+		}
+		res = append(res, i)
+		constant = constant && i.constant
+	}
+
+	x := res[0]
+	switch {
+	case x.kind == KindIllegal:
+		return illegal
+	case constant:
+		return c.eval(sliceExpr)
+	case x.kind == KindAny:
+		return checked{kind: KindAny}
+	case x.kind != KindString:
+		vx := c.visitor(x, sliceExpr.X)
+		defer pool.Put(vx)
+		return c.report(newNonIndexableError(vx, sliceExpr.X))
+	case sliceExpr.Slice3:
+		return c.report(newSlice3Error(sliceExpr))
+	}
+
+	for i, e := 1, 0; e < len(indexes); e++ {
+		if indexes[e] == nil {
+			continue
+		}
+		if !c.checkIndexValue(res[i], indexes[e]) {
+			return illegal
+		}
+		i++
+	}
+	return c.variable(KindString)
+}
+
+// checkIndexValue checks whether i that is checked from e can be used as an index.
+func (c *checker) checkIndexValue(i checked, e ast.Expr) bool {
+	if i.kind == KindAny {
+		return true
+	}
+
+	v, vi := c.visitor(checked{}, e), c.visitor(i, e)
+	defer pool.Put(v)
+	defer pool.Put(vi)
+
+	if _, ok := v.toIndex(vi, e); !ok {
+		c.report(v.err)
+		return false
+	}
+	return true
+}
+
+func (c *checker) checkCompositeLit(compositeLit *ast.CompositeLit) checked {
+	if err := validateCompositeLit(compositeLit); err != nil {
+		return c.report(err)
+	}
+
+	constant, ok := true, true
+	for _, elt := range compositeLit.Elts {
+		res := c.check(elt)
+		constant = constant && res.constant
+		ok = ok && res.kind != KindIllegal
+	}
+	switch {
+	case !ok:
+		return illegal
+	case constant:
+		return c.eval(compositeLit)
+	}
+	return c.variable(KindList)
+}
+
+// commonKind returns the Kind of the given results if all of them have the same Kind, otherwise KindAny.
+func commonKind(res ...checked) Kind {
+	kind := KindIllegal
+	for i := range res {
+		switch {
+		case kind == KindIllegal:
+			kind = res[i].kind
+		case kind != res[i].kind:
+			return KindAny
+		}
+	}
+	return kind
+}
@@ -0,0 +1,186 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	schema := Schema{
+		"qty":     KindInt,
+		"count":   KindUint,
+		"price":   KindFloat,
+		"z":       KindImag,
+		"tier":    KindString,
+		"active":  KindBoolean,
+		"order":   KindObject,
+		"tags":    KindList,
+		"payload": KindAny,
+		"coupon":  KindNil,
+	}
+	funcs := WithFunctions(map[string]Func{
+		"tier": {Args: []Kind{KindInt}, Return: KindFloat, Fn: func(args ...interface{}) (interface{}, error) {
+			panic("function must not be called by Check")
+		}},
+		"any": {Args: []Kind{KindAny}, Return: KindAny},
+	})
+
+	tt := []struct {
+		in           string
+		options      []Option
+		expectedKind Kind
+		expectedErrs []error
+		expectedPos  int // position of the first error
+	}{
+		{in: `qty > 100 && tier == "gold"`, expectedKind: KindBoolean},
+		{in: `price - price*tier(qty)`, expectedKind: KindFloat},
+		{in: `qty * 2`, expectedKind: KindFloat}, // NumericTypeAuto calculates using float
+		{in: `qty * 2`, options: []Option{WithNumericType(NumericTypeGo)}, expectedKind: KindInt},
+		{in: `qty / 0`, options: []Option{WithNumericType(NumericTypeGo)}, expectedKind: KindInt},
+		{in: `qty / 0`, options: []Option{WithNumericType(NumericTypeGo), WithAllowIntegerDividedByZero(false)}, expectedErrs: []error{ErrIntegerDividedByZero}},
+		{in: `qty + 9223372036854775807`, options: []Option{WithNumericType(NumericTypeGo), WithOverflowPolicy(OverflowError)}, expectedKind: KindAny},
+		{in: `qty - qty`, options: []Option{WithNumericType(NumericTypeGo)}, expectedKind: KindInt},
+		{in: `1 / (qty - qty)`, options: []Option{WithNumericType(NumericTypeGo), WithAllowIntegerDividedByZero(false)}, expectedKind: KindInt},
+		{in: `count - 2`, expectedKind: KindAny}, // uint64 if count >= 2, otherwise int64
+		{in: `count * qty`, options: []Option{WithNumericType(NumericTypeGo)}, expectedKind: KindAny},
+		{in: `-count`, expectedKind: KindAny}, // -0 is uint64
+		{in: `count - count`, expectedKind: KindUint},
+		{in: `count - 2`, options: []Option{WithNumericType(NumericTypeUint)}, expectedKind: KindUint},
+		{in: `count - 2 > 0`, expectedKind: KindBoolean},
+		{in: `z * 2`, expectedKind: KindImag},
+		{in: `"a" + 1`, expectedErrs: []error{ErrArithmeticOperation}, expectedPos: 7},
+		{in: `tier + 1`, expectedErrs: []error{ErrArithmeticOperation}, expectedPos: 8},
+		{in: `!5`, expectedErrs: []error{ErrUnaryOperation}, expectedPos: 2},
+		{in: `!qty`, expectedErrs: []error{ErrUnaryOperation}, expectedPos: 2},
+		{in: `^qty`, expectedErrs: []error{ErrUnsupportedOperator}, expectedPos: 1},
+		{in: `true < false`, expectedErrs: []error{ErrUnsupportedOperator}, expectedPos: 6},
+		{in: `z % 2`, expectedErrs: []error{ErrArithmeticOperation}, expectedPos: 3},
+		{in: `qty && active`, expectedErrs: []error{ErrLogicalOperation}, expectedPos: 1},
+		{in: `active || qty`, expectedErrs: []error{ErrLogicalOperation}, expectedPos: 11}, // checked although short-circuited
+		{in: `price & 1`, expectedKind: KindInt},
+		{in: `tier & 1`, expectedErrs: []error{ErrBitwiseOperation}},
+		{in: `!5 || tier + 1 > 0 || "a" < 1`, expectedErrs: []error{ErrUnaryOperation, ErrArithmeticOperation, ErrComparisonOperation}, expectedPos: 2},
+		{in: `(tier + 1) * 2`, expectedErrs: []error{ErrArithmeticOperation}}, // reported once
+		{in: `payload + 1`, expectedKind: KindAny},
+		{in: `payload > 1 && payload`, expectedKind: KindBoolean},
+		{in: `payload && 1`, expectedErrs: []error{ErrLogicalOperation}},
+		{in: `!payload`, expectedKind: KindBoolean},
+		{in: `-payload`, expectedKind: KindAny},
+		{in: `order.customer.tier`, expectedKind: KindAny},
+		{in: `order["items"][0]`, expectedKind: KindAny},
+		{in: `qty.x`, expectedErrs: []error{ErrIndexOperation}, expectedPos: 5},
+		{in: `qty[0]`, expectedErrs: []error{ErrIndexOperation}},
+		{in: `tier[0]`, expectedKind: KindUint},
+		{in: `tier[qty]`, expectedKind: KindUint},
+		{in: `tier[price]`, expectedKind: KindUint}, // float without fractional part is accepted on evaluation
+		{in: `tier["a"]`, expectedErrs: []error{ErrIndexOperation}},
+		{in: `tier[1:qty]`, expectedKind: KindString},
+		{in: `tier[1:2:3]`, expectedErrs: []error{ErrIndexOperation}},
+		{in: `qty[1:]`, expectedErrs: []error{ErrIndexOperation}},
+		{in: `"abc"[5]`, expectedErrs: []error{ErrIndexOutOfRange}}, // constant
+		{in: `tags[0]`, expectedKind: KindAny},
+		{in: `[]any{1, 2}[1]`, expectedKind: KindInt},
+		{in: `[]any{qty, 2}`, expectedKind: KindList},
+		{in: `map[string]int{}`, expectedErrs: []error{ErrUnsupportedSyntax}},
+		{in: `in(tier, []any{"gold", "silver"})`, expectedKind: KindBoolean},
+		{in: `in(tier, tags)`, expectedKind: KindBoolean},
+		{in: `in(qty, tier)`, expectedErrs: []error{ErrFunctionCall}, expectedPos: 9},
+		{in: `in(qty)`, expectedErrs: []error{ErrFunctionCall}},
+		{in: `iif(qty > 100, 0.1, 0.2)`, expectedKind: KindFloat},
+		{in: `iif(qty > 100, 0.1, "none")`, expectedKind: KindAny},
+		{in: `iif(true, 0.1, "none")`, expectedKind: KindFloat},
+		{in: `iif(qty, 1, 0)`, expectedErrs: []error{ErrLogicalOperation}, expectedPos: 5},
+		{in: `coalesce(coupon, tier)`, expectedKind: KindString},
+		{in: `coalesce(payload, tier)`, expectedKind: KindAny},
+		{in: `coalesce(nil, coupon)`, expectedKind: KindNil},
+		{in: `coupon == nil`, expectedKind: KindBoolean},
+		{in: `coupon + 1`, expectedErrs: []error{ErrArithmeticOperation}},
+		{in: `coupon + 1`, options: []Option{WithNilPropagation(true)}, expectedKind: KindNil},
+		{in: `int(price) + qty`, options: []Option{WithNumericType(NumericTypeGo)}, expectedKind: KindInt},
+		{in: `int(tier)`, expectedKind: KindInt}, // parsing error is only known on evaluation
+		{in: `int(active)`, expectedErrs: []error{ErrFunctionCall}},
+		{in: `int("a")`, expectedErrs: []error{ErrFunctionCall}}, // constant
+		{in: `string(qty) + tier`, expectedKind: KindString},
+		{in: `bool(qty)`, expectedErrs: []error{ErrFunctionCall}},
+		{in: `tier(price)`, expectedKind: KindFloat}, // price might not have fractional part
+		{in: `tier(tier)`, expectedErrs: []error{ErrFunctionCall}, expectedPos: 6},
+		{in: `tier(payload)`, expectedKind: KindFloat},
+		{in: `any(1) + 1`, expectedKind: KindAny},
+		{in: `tier(1, 2)`, expectedErrs: []error{ErrFunctionCall}},
+		{in: `unknown(1)`, expectedErrs: []error{ErrFunctionCall}},
+		{in: `x + 1`, expectedErrs: []error{ErrArithmeticOperation}}, // undeclared identifier is treated as string
+		{in: `x + 1`, options: []Option{WithStrictIdents(true)}, expectedErrs: []error{ErrUnknownIdentifier}},
+		{in: `pi * 2`, options: []Option{WithMath()}, expectedKind: KindFloat},
+		{in: `floor(price) + sqrt(qty)`, options: []Option{WithMath()}, expectedKind: KindAny},
+		{in: `*qty`, expectedErrs: []error{ErrUnsupportedSyntax}},
+		{in: `qty + 1`, options: []Option{WithMaxInputLen(3)}, expectedErrs: []error{ErrLimitExceeded}},
+	}
+
+	for i, tc := range tt {
+		tc := tc
+		t.Run(fmt.Sprintf("[%d] %s", i, tc.in), func(t *testing.T) {
+			res, err := Check(tc.in, schema, append([]Option{funcs}, tc.options...)...)
+			for _, expectedErr := range tc.expectedErrs {
+				if !errors.Is(err, expectedErr) {
+					t.Fatalf("expected err: %v, got: %v", expectedErr, err)
+				}
+			}
+			if len(tc.expectedErrs) == 0 && err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+			if checkErr, ok := err.(*CheckError); ok && len(checkErr.Errs) != len(tc.expectedErrs) {
+				t.Fatalf("expected %d errors, got: %v", len(tc.expectedErrs), err)
+			}
+			if tc.expectedPos != 0 {
+				var syntaxErr *SyntaxError
+				if !errors.As(err, &syntaxErr) || syntaxErr.Pos != tc.expectedPos {
+					t.Fatalf("expected pos: %d, got: %v", tc.expectedPos, err)
+				}
+			}
+			if err != nil {
+				return
+			}
+			if res.Kind != tc.expectedKind {
+				t.Fatalf("expected kind: %s, got: %s", tc.expectedKind, res.Kind)
+			}
+			if last := res.Exprs[len(res.Exprs)-1]; last.Kind != res.Kind || last.Expr != tc.in {
+				t.Fatalf("expected the last expr: %s (%s), got: %s (%s)", tc.in, res.Kind, last.Expr, last.Kind)
+			}
+		})
+	}
+}
+
+func TestCheckExprs(t *testing.T) {
+	res, err := Check(`qty * 2 > limit`, Schema{"qty": KindInt, "limit": KindFloat}, WithNumericType(NumericTypeGo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []TypedExpr{
+		{Expr: "qty", Pos: 1, Kind: KindInt},
+		{Expr: "2", Pos: 7, Kind: KindInt},
+		{Expr: "qty * 2", Pos: 1, Kind: KindInt},
+		{Expr: "limit", Pos: 11, Kind: KindFloat},
+		{Expr: "qty * 2 > limit", Pos: 1, Kind: KindBoolean},
+	}
+	if fmt.Sprint(res.Exprs) != fmt.Sprint(expected) {
+		t.Fatalf("expected: %v, got: %v", expected, res.Exprs)
+	}
+
+	if _, err := Check("(1 + 1", nil); err == nil {
+		t.Fatalf("expected parser error, got: nil")
+	}
+}
@@ -21,9 +21,26 @@ import (
 	"github.com/muktihari/expr/internal/conv"
 )
 
-// comparison compares visitor X and visitor Y values. Numeric hierarchy will apply: complex128 > float64 > int64.
+// comparison compares visitor X and visitor Y values. Numeric hierarchy will apply: complex128 > float64 > int64,
+// big numbers and decimals are compared without losing precision: Decimal > *big.Float > *big.Int.
 func comparison(v, vx, vy *Visitor, binaryExpr *ast.BinaryExpr) {
 	v.value.SetKind(KindBoolean)
+	if vx.value.Kind() == KindNil || vy.value.Kind() == KindNil {
+		compareNil(v, vx, vy, binaryExpr)
+		return
+	}
+	if (isDecimal(vx.value) || isDecimal(vy.value)) && isNumeric(vx.value) && isNumeric(vy.value) {
+		compareDecimal(v, vx, vy, binaryExpr)
+		return
+	}
+	if (isBig(vx.value) || isBig(vy.value)) && isNumeric(vx.value) && isNumeric(vy.value) {
+		compareBig(v, vx, vy, binaryExpr)
+		return
+	}
+	if (isUint(vx.value) || isUint(vy.value)) && isNumeric(vx.value) && isNumeric(vy.value) {
+		compareUint(v, vx, vy, binaryExpr)
+		return
+	}
 	switch vx.value.Kind() {
 	case KindImag:
 		// Treat y as complex number since x is a complex number.
@@ -0,0 +1,177 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"fmt"
+	"go/ast"
+	"strconv"
+
+	"github.com/muktihari/expr/internal/conv"
+)
+
+// WithLosslessConversion makes conversion that would lose information returns an error instead of truncating it,
+// e.g. "int(3.9)" and "float64(2+1i)" return error while "int(3.0)" -> 3 and "float64(2+0i)" -> 2.0.
+func WithLosslessConversion(v bool) Option {
+	return func(o *options) { o.losslessConversion = v }
+}
+
+// visitConversionArg evaluates the only argument of conversion call.
+func visitConversionArg(v *Visitor, callExpr *ast.CallExpr) (value, bool) {
+	va := pool.Get().(*Visitor)
+	defer pool.Put(va)
+	va.reset(v.options)
+
+	va.Visit(callExpr.Args[0])
+	if va.err != nil {
+		v.err = va.err
+		return value{}, false
+	}
+	return va.value, true
+}
+
+// builtinInt converts its argument into int64: int(3.9) -> 3, int("42") -> 42. Numeric value is truncated the same
+// way as NumericTypeInt, unless WithLosslessConversion is used.
+func builtinInt(v *Visitor, callExpr *ast.CallExpr) {
+	val, ok := visitConversionArg(v, callExpr)
+	if !ok {
+		return
+	}
+
+	switch {
+	case val.Kind() == KindString:
+		i, err := strconv.ParseInt(val.String(), 0, 64)
+		if err != nil {
+			v.err = newConversionError(val, callExpr, err)
+			return
+		}
+		v.value = int64Value(i)
+	case !isNumeric(val):
+		v.err = newConversionError(val, callExpr, nil)
+	case v.options.losslessConversion:
+		i, ok := convertArg(val, KindInt, NumericTypeAuto)
+		if !ok {
+			v.err = newConversionError(val, callExpr, nil)
+			return
+		}
+		v.value = int64Value(i.(int64))
+	default:
+		v.value = int64Value(parseInt(runeAsInt(val)))
+	}
+}
+
+// builtinFloat64 converts its argument into float64: float64(7) -> 7.0, float64("2.5") -> 2.5. Complex's imaginary
+// part is discarded, unless WithLosslessConversion is used.
+func builtinFloat64(v *Visitor, callExpr *ast.CallExpr) {
+	val, ok := visitConversionArg(v, callExpr)
+	if !ok {
+		return
+	}
+
+	switch {
+	case val.Kind() == KindString:
+		f, err := strconv.ParseFloat(val.String(), 64)
+		if err != nil {
+			v.err = newConversionError(val, callExpr, err)
+			return
+		}
+		v.value = float64Value(f)
+	case !isNumeric(val):
+		v.err = newConversionError(val, callExpr, nil)
+	case v.options.losslessConversion:
+		f, ok := convertArg(val, KindFloat, NumericTypeAuto)
+		if !ok {
+			v.err = newConversionError(val, callExpr, nil)
+			return
+		}
+		v.value = float64Value(f.(float64))
+	default:
+		v.value = float64Value(parseFloat(runeAsInt(val)))
+	}
+}
+
+// builtinString converts its argument into string: string(42) -> "42", string(true) -> "true". Unlike Go,
+// integer is formatted as number, only rune is converted into its character: string('a') -> "a".
+func builtinString(v *Visitor, callExpr *ast.CallExpr) {
+	val, ok := visitConversionArg(v, callExpr)
+	if !ok {
+		return
+	}
+
+	switch {
+	case val.Kind() == KindRune:
+		v.value = stringValue(string(rune(val.Int64())))
+	case val.Kind() == KindBoolean:
+		v.value = stringValue(strconv.FormatBool(val.Bool()))
+	default:
+		s, ok := coerceString(val, true)
+		if !ok {
+			v.err = newConversionError(val, callExpr, nil)
+			return
+		}
+		v.value = stringValue(s)
+	}
+}
+
+// builtinBool converts its argument into boolean: bool("true") -> true, string is parsed using strconv.ParseBool.
+func builtinBool(v *Visitor, callExpr *ast.CallExpr) {
+	val, ok := visitConversionArg(v, callExpr)
+	if !ok {
+		return
+	}
+
+	switch val.Kind() {
+	case KindBoolean:
+		v.value = val
+	case KindString:
+		b, err := strconv.ParseBool(val.String())
+		if err != nil {
+			v.err = newConversionError(val, callExpr, err)
+			return
+		}
+		v.value = boolValue(b)
+	default:
+		v.err = newConversionError(val, callExpr, nil)
+	}
+}
+
+// checkConversion creates conversion's checker for Check, the argument is valid if it satisfies accept or its Kind
+// is one of kinds. Value dependent error such as parsing a string is only reported on evaluation.
+func checkConversion(result Kind, accept func(val value) bool, kinds ...Kind) func(c *checker, callExpr *ast.CallExpr, args []checked) checked {
+	return func(c *checker, callExpr *ast.CallExpr, args []checked) checked {
+		if args[0].kind == KindAny || (accept != nil && accept(args[0].value)) {
+			return c.variable(result)
+		}
+		for _, kind := range kinds {
+			if args[0].kind == kind {
+				return c.variable(result)
+			}
+		}
+		return c.report(newConversionError(args[0].value, callExpr, nil))
+	}
+}
+
+// newConversionError creates conversion error, err is the underlying parsing error if any.
+func newConversionError(val value, callExpr *ast.CallExpr, err error) error {
+	msg := fmt.Sprintf("could not convert %q into %s: result is \"%v\"",
+		conv.FormatExpr(callExpr.Args[0]), conv.FormatExpr(callExpr.Fun), val.Any())
+	if err != nil {
+		msg += ": " + err.Error()
+	}
+	return &SyntaxError{
+		Msg: msg,
+		Pos: int(callExpr.Args[0].Pos()),
+		Err: ErrFunctionCall,
+	}
+}
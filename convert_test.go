@@ -0,0 +1,105 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"testing"
+)
+
+func TestConversion(t *testing.T) {
+	tt := []struct {
+		in           string
+		options      []Option
+		expectedKind Kind
+		expectedStr  string
+		expectedErr  error
+		expectedPos  int
+	}{
+		{in: `int(3.9)`, expectedKind: KindInt, expectedStr: "3"},
+		{in: `int(-3.9)`, expectedKind: KindInt, expectedStr: "-3"},
+		{in: `int(3.9) * 2`, options: []Option{WithNumericType(NumericTypeGo)}, expectedKind: KindInt, expectedStr: "6"},
+		{in: `int(3.0)`, options: []Option{WithLosslessConversion(true)}, expectedKind: KindInt, expectedStr: "3"},
+		{in: `int(3.9)`, options: []Option{WithLosslessConversion(true)}, expectedErr: ErrFunctionCall, expectedPos: 5},
+		{in: `int(2+1i)`, expectedKind: KindInt, expectedStr: "2"},
+		{in: `int(3.9)`, options: []Option{WithNumericType(NumericTypeDecimal)}, expectedKind: KindInt, expectedStr: "3"},
+		{in: `int(3.9)`, options: []Option{WithNumericType(NumericTypeBig)}, expectedKind: KindInt, expectedStr: "3"},
+		{in: `int('a')`, expectedKind: KindInt, expectedStr: "97"},
+		{in: `int("42")`, expectedKind: KindInt, expectedStr: "42"},
+		{in: `int("0x2a")`, expectedKind: KindInt, expectedStr: "42"},
+		{in: `int("4.2")`, expectedErr: ErrFunctionCall, expectedPos: 5},
+		{in: `int(true)`, expectedErr: ErrFunctionCall, expectedPos: 5},
+		{in: `float64(7) / 2`, options: []Option{WithNumericType(NumericTypeGo)}, expectedKind: KindFloat, expectedStr: "3.5"},
+		{in: `7 / 2`, options: []Option{WithNumericType(NumericTypeGo)}, expectedKind: KindInt, expectedStr: "3"},
+		{in: `float64(2+1i)`, expectedKind: KindFloat, expectedStr: "2"},
+		{in: `float64(2+1i)`, options: []Option{WithLosslessConversion(true)}, expectedErr: ErrFunctionCall},
+		{in: `float64("2.5")`, expectedKind: KindFloat, expectedStr: "2.5"},
+		{in: `float64("a")`, expectedErr: ErrFunctionCall},
+		{in: `float64(nil)`, expectedErr: ErrFunctionCall},
+		{in: `string(42)`, expectedKind: KindString, expectedStr: "42"},
+		{in: `string(2.5) + "%"`, expectedKind: KindString, expectedStr: "2.5%"},
+		{in: `string('a')`, expectedKind: KindString, expectedStr: "a"},
+		{in: `string(true)`, expectedKind: KindString, expectedStr: "true"},
+		{in: `string("a")`, expectedKind: KindString, expectedStr: "a"},
+		{in: `string(nil)`, expectedErr: ErrFunctionCall, expectedPos: 8},
+		{in: `bool("true")`, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `bool(1 > 2)`, expectedKind: KindBoolean, expectedStr: "false"},
+		{in: `bool("yes")`, expectedErr: ErrFunctionCall},
+		{in: `bool(1)`, expectedErr: ErrFunctionCall},
+		{in: `int(1 + true)`, expectedErr: ErrArithmeticOperation},
+		{in: `int(1, 2)`, expectedErr: ErrFunctionCall, expectedPos: 8},
+		{in: `int(3.9)`, expectedKind: KindString, expectedStr: "overridden",
+			options: []Option{WithFunctions(map[string]Func{
+				"int": {Args: []Kind{KindAny}, Return: KindString, Fn: func(args ...interface{}) (interface{}, error) {
+					return "overridden", nil
+				}},
+			})},
+		},
+	}
+
+	for i, tc := range tt {
+		tc := tc
+		t.Run(fmt.Sprintf("[%d] %s", i, tc.in), func(t *testing.T) {
+			e, err := parser.ParseExpr(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := NewVisitor(tc.options...)
+			ast.Walk(v, e)
+
+			if err := v.Err(); !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected err: %v, got: %v", tc.expectedErr, err)
+			}
+			if tc.expectedPos != 0 {
+				var syntaxErr *SyntaxError
+				if !errors.As(v.Err(), &syntaxErr) || syntaxErr.Pos != tc.expectedPos {
+					t.Fatalf("expected pos: %d, got: %v", tc.expectedPos, v.Err())
+				}
+			}
+			if tc.expectedErr != nil {
+				return
+			}
+			if v.Kind() != tc.expectedKind {
+				t.Fatalf("expected kind: %s, got: %s", tc.expectedKind, v.Kind())
+			}
+			if str := fmt.Sprint(v.ValueAny()); str != tc.expectedStr {
+				t.Fatalf("expected val: %s, got: %s", tc.expectedStr, str)
+			}
+		})
+	}
+}
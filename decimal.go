@@ -0,0 +1,420 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/muktihari/expr/internal/conv"
+)
+
+// DefaultDecimalScale is the default maximum number of digits after the decimal point used by NumericTypeDecimal.
+const DefaultDecimalScale = 16
+
+// maxDecimalExponent limits the absolute exponent of a decimal string, e.g. "1e-65536" is accepted while "1e-65537" is
+// out of range.
+const maxDecimalExponent = 1 << 16
+
+// RoundingMode is the rounding mode used by NumericTypeDecimal when a result has more digits than the scale.
+type RoundingMode byte
+
+const (
+	RoundHalfEven RoundingMode = iota // round to nearest, ties to even: 2.5 -> 2, 3.5 -> 4 (default)
+	RoundHalfUp                       // round to nearest, ties away from zero: 2.5 -> 3, -2.5 -> -3
+	RoundDown                         // round toward zero (truncate): 2.9 -> 2, -2.9 -> -2
+)
+
+var roundingModes = [...]string{
+	RoundHalfEven: "RoundHalfEven",
+	RoundHalfUp:   "RoundHalfUp",
+	RoundDown:     "RoundDown",
+}
+
+func (m RoundingMode) String() string {
+	if int(m) < len(roundingModes) {
+		return roundingModes[m]
+	}
+	return "RoundingMode(" + strconv.Itoa(int(m)) + ")"
+}
+
+var errDecimalSyntax = errors.New("invalid decimal syntax")
+
+// Decimal is an arbitrary-precision base-10 fixed-point number: unscaled * 10^-scale.
+// The zero value is 0. Decimal is immutable, so it is safe to be copied and shared.
+type Decimal struct {
+	unscaled *big.Int // nil means 0
+	scale    int      // number of digits after the decimal point, never negative.
+}
+
+// NewDecimal creates Decimal of unscaled * 10^-scale, e.g. NewDecimal(1999, 2) is 19.99.
+// Negative scale multiplies unscaled, e.g. NewDecimal(2, -3) is 2000.
+func NewDecimal(unscaled int64, scale int) Decimal {
+	return newDecimal(big.NewInt(unscaled), scale)
+}
+
+// newDecimal creates Decimal of unscaled * 10^-scale, it takes the ownership of unscaled.
+func newDecimal(unscaled *big.Int, scale int) Decimal {
+	if scale < 0 {
+		return Decimal{unscaled: unscaled.Mul(unscaled, pow10(-scale))}
+	}
+	return Decimal{unscaled: unscaled, scale: scale}
+}
+
+// ParseDecimal parses s into Decimal exactly, e.g. "0.1", "-12.50", "1.5e3" and "25e-4".
+// The scale of the resulting Decimal is the number of digits after the decimal point, "12.50" has scale 2.
+func ParseDecimal(s string) (Decimal, error) {
+	str := s
+	neg := false
+	if len(str) > 0 && (str[0] == '+' || str[0] == '-') {
+		neg = str[0] == '-'
+		str = str[1:]
+	}
+
+	exp := 0
+	if i := strings.IndexAny(str, "eE"); i >= 0 {
+		e, err := strconv.Atoi(str[i+1:])
+		if err != nil || e > maxDecimalExponent || e < -maxDecimalExponent {
+			if err == nil || errors.Is(err, strconv.ErrRange) {
+				return Decimal{}, &strconv.NumError{Func: "ParseDecimal", Num: s, Err: strconv.ErrRange}
+			}
+			return Decimal{}, &strconv.NumError{Func: "ParseDecimal", Num: s, Err: errDecimalSyntax}
+		}
+		exp, str = e, str[:i]
+	}
+
+	digits, scale := str, 0
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		digits, scale = str[:i]+str[i+1:], len(str)-i-1
+	}
+	if digits == "" || strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+		return Decimal{}, &strconv.NumError{Func: "ParseDecimal", Num: s, Err: errDecimalSyntax}
+	}
+
+	unscaled, _ := new(big.Int).SetString(digits, 10)
+	if neg {
+		unscaled.Neg(unscaled)
+	}
+	return newDecimal(unscaled, scale-exp), nil
+}
+
+// pow10 returns 10^n, n must not be negative.
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+func (d Decimal) int() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int { return d.scale }
+
+// Sign returns -1 if d < 0, 0 if d == 0 and +1 if d > 0.
+func (d Decimal) Sign() int { return d.int().Sign() }
+
+// Cmp compares d and y, it returns -1 if d < y, 0 if d == y and +1 if d > y. The scale is not compared, 1.50 == 1.5.
+func (d Decimal) Cmp(y Decimal) int {
+	x, yy := alignDecimal(d, y)
+	return x.Cmp(yy)
+}
+
+// String returns d in decimal notation with exactly Scale digits after the decimal point, e.g. "-12.50".
+func (d Decimal) String() string {
+	s := new(big.Int).Abs(d.int()).String()
+	if d.scale > 0 {
+		if len(s) <= d.scale {
+			s = strings.Repeat("0", d.scale-len(s)+1) + s
+		}
+		s = s[:len(s)-d.scale] + "." + s[len(s)-d.scale:]
+	}
+	if d.Sign() < 0 {
+		return "-" + s
+	}
+	return s
+}
+
+// Float64 returns the nearest float64 value of d.
+func (d Decimal) Float64() float64 {
+	f, _ := d.Rat().Float64()
+	return f
+}
+
+// Rat returns d as *big.Rat.
+func (d Decimal) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(d.int(), pow10(d.scale))
+}
+
+// IsInt reports whether d has no fractional part.
+func (d Decimal) IsInt() bool {
+	if d.scale == 0 {
+		return true
+	}
+	return new(big.Int).Rem(d.int(), pow10(d.scale)).Sign() == 0
+}
+
+// round returns d with at most scale digits after the decimal point, rounded using mode.
+func (d Decimal) round(scale int, mode RoundingMode) Decimal {
+	if d.scale <= scale {
+		return d
+	}
+	return Decimal{unscaled: roundQuo(d.int(), pow10(d.scale-scale), mode), scale: scale}
+}
+
+// roundQuo returns x / y rounded using mode, y must be positive.
+func roundQuo(x, y *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(x, y, new(big.Int))
+	if r.Sign() == 0 || mode == RoundDown {
+		return q
+	}
+	cmp := r.Abs(r).Lsh(r, 1).Cmp(y) // compare 2|r| with y
+	if cmp > 0 || (cmp == 0 && (mode == RoundHalfUp || q.Bit(0) == 1)) {
+		if x.Sign() < 0 {
+			return q.Sub(q, big.NewInt(1))
+		}
+		return q.Add(q, big.NewInt(1))
+	}
+	return q
+}
+
+// decimalFromRat converts r into Decimal. It is exact if r can be represented within scale digits,
+// otherwise it is rounded using mode.
+func decimalFromRat(r *big.Rat, scale int, mode RoundingMode) Decimal {
+	if s, ok := exactDecimalScale(r.Denom()); ok && s <= scale {
+		num := new(big.Int).Mul(r.Num(), pow10(s))
+		return Decimal{unscaled: num.Quo(num, r.Denom()), scale: s}
+	}
+	num := new(big.Int).Mul(r.Num(), pow10(scale))
+	return Decimal{unscaled: roundQuo(num, r.Denom(), mode), scale: scale}
+}
+
+// exactDecimalScale returns the smallest n where 10^n is divisible by den, it returns false if there is none.
+func exactDecimalScale(den *big.Int) (int, bool) {
+	twos := int(den.TrailingZeroBits())
+	d := new(big.Int).Rsh(den, uint(twos))
+	fives, five, m := 0, big.NewInt(5), new(big.Int)
+	for d.Cmp(bigOne) != 0 {
+		q, r := new(big.Int).QuoRem(d, five, m)
+		if r.Sign() != 0 {
+			return 0, false
+		}
+		d, fives = q, fives+1
+	}
+	if twos > fives {
+		return twos, true
+	}
+	return fives, true
+}
+
+var bigOne = big.NewInt(1)
+
+// alignDecimal returns the unscaled values of x and y in the same scale.
+func alignDecimal(x, y Decimal) (*big.Int, *big.Int) {
+	switch {
+	case x.scale < y.scale:
+		return new(big.Int).Mul(x.int(), pow10(y.scale-x.scale)), y.int()
+	case x.scale > y.scale:
+		return x.int(), new(big.Int).Mul(y.int(), pow10(x.scale-y.scale))
+	}
+	return x.int(), y.int()
+}
+
+// parseDecimalLiteral parses Go's INT or FLOAT literal into Decimal exactly, including hexadecimal float, e.g. 0x1p-2 -> 0.25.
+func parseDecimalLiteral(lit string) (Decimal, error) {
+	lit = strings.Replace(lit, "_", "", -1)
+	if len(lit) < 2 || lit[0] != '0' || strings.IndexAny(lit[1:2], ".eE") >= 0 ||
+		strings.IndexAny(lit[1:2], "0123456789") >= 0 && strings.IndexAny(lit, ".eE") >= 0 {
+		return ParseDecimal(lit) // base 10, while INT literal "017" is a legacy octal: 15, the same as Go.
+	}
+	if strings.IndexAny(lit[:2], "xX") < 0 || strings.IndexAny(lit, "pP.") < 0 {
+		i, ok := new(big.Int).SetString(lit, 0)
+		if !ok {
+			return Decimal{}, &strconv.NumError{Func: "ParseDecimal", Num: lit, Err: errDecimalSyntax}
+		}
+		return Decimal{unscaled: i}, nil
+	}
+	f, _, err := big.ParseFloat(lit, 0, 0, big.ToNearestEven) // hexadecimal float is always exact in base-10.
+	if err != nil {
+		return Decimal{}, err
+	}
+	r, _ := f.Rat(nil)
+	return decimalFromRat(r, math.MaxInt32, RoundHalfEven), nil
+}
+
+// isDecimal reports whether val is a Decimal.
+func isDecimal(val value) bool { return val.Kind() == KindDecimal }
+
+// parseDecimal converts numeric val into Decimal, float is converted using its shortest representation: 0.1 -> 0.1.
+// It returns false if val is NaN, Inf or a complex number.
+func parseDecimal(val value) (Decimal, bool) {
+	switch val.Kind() {
+	case KindDecimal:
+		return val.Decimal(), true
+	case KindInt, KindRune:
+		return NewDecimal(val.Int64(), 0), true
+	case KindUint:
+		return Decimal{unscaled: new(big.Int).SetUint64(val.Uint64())}, true
+	case KindBigInt:
+		return Decimal{unscaled: val.BigInt()}, true
+	case KindFloat:
+		f := val.Float64()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return Decimal{}, false
+		}
+		d, err := ParseDecimal(strconv.FormatFloat(f, 'g', -1, 64))
+		return d, err == nil
+	case KindBigFloat:
+		f := val.BigFloat()
+		if f.IsInf() {
+			return Decimal{}, false
+		}
+		d, err := ParseDecimal(f.Text('g', -1))
+		return d, err == nil
+	}
+	return Decimal{}, false
+}
+
+// calculateDecimal does arithmetic operation on decimals, the result is rounded to the decimal scale if it
+// has more digits than the scale. Mixing with a complex number promotes into complex128.
+func calculateDecimal(v *Visitor, vx, vy *Visitor, binaryExpr *ast.BinaryExpr) {
+	if vx.value.Kind() == KindImag || vy.value.Kind() == KindImag {
+		calculateComplex(v, parseComplex(vx.value), parseComplex(vy.value), binaryExpr.Op, binaryExpr.OpPos)
+		return
+	}
+
+	x, ok := parseDecimal(vx.value)
+	if !ok {
+		v.err = newDecimalConversionError(vx, binaryExpr.X)
+		return
+	}
+	y, ok := parseDecimal(vy.value)
+	if !ok {
+		v.err = newDecimalConversionError(vy, binaryExpr.Y)
+		return
+	}
+
+	scale, mode := v.options.decimalScale, v.options.decimalRounding
+	var z Decimal
+	switch binaryExpr.Op {
+	case token.ADD:
+		ux, uy := alignDecimal(x, y)
+		z = Decimal{unscaled: new(big.Int).Add(ux, uy), scale: maxInt(x.scale, y.scale)}
+	case token.SUB:
+		ux, uy := alignDecimal(x, y)
+		z = Decimal{unscaled: new(big.Int).Sub(ux, uy), scale: maxInt(x.scale, y.scale)}
+	case token.MUL:
+		z = Decimal{unscaled: new(big.Int).Mul(x.int(), y.int()), scale: x.scale + y.scale}
+	case token.QUO, token.REM:
+		if y.Sign() == 0 {
+			v.value = value{}
+			v.err = &SyntaxError{
+				Msg: "could not divide x with zero y",
+				Pos: vy.pos,
+				Err: ErrArithmeticOperation,
+			}
+			return
+		}
+		if binaryExpr.Op == token.QUO {
+			z = decimalFromRat(new(big.Rat).Quo(x.Rat(), y.Rat()), scale, mode)
+			break
+		}
+		ux, uy := alignDecimal(x, y)
+		z = Decimal{unscaled: new(big.Int).Rem(ux, uy), scale: maxInt(x.scale, y.scale)} // truncated like Go
+	}
+	v.value = decimalValue(z.round(scale, mode))
+}
+
+func maxInt(x, y int) int {
+	if x > y {
+		return x
+	}
+	return y
+}
+
+func newDecimalConversionError(v *Visitor, e ast.Expr) error {
+	s := conv.FormatExpr(e)
+	return &SyntaxError{
+		Msg: "result of \"" + s + "\" is \"" + fmt.Sprintf("%v", v.value.Any()) + "\" which is not representable as Decimal",
+		Pos: v.pos,
+		Err: ErrArithmeticOperation,
+	}
+}
+
+// compareDecimal compares decimals exactly, mixing with a complex number will be compared as complex128.
+// NaN is not equal to any Decimal and Inf is compared as the greatest or the lowest number.
+func compareDecimal(v, vx, vy *Visitor, binaryExpr *ast.BinaryExpr) {
+	if vx.value.Kind() == KindImag || vy.value.Kind() == KindImag {
+		compareComplex(v, parseComplex(vx.value), parseComplex(vy.value), binaryExpr.Op, binaryExpr.OpPos)
+		return
+	}
+
+	x, xok := parseDecimal(vx.value)
+	y, yok := parseDecimal(vy.value)
+	if !xok || !yok { // NaN or Inf, float64 is sufficient to compare them.
+		compareFloat(v, parseFloat(vx.value), parseFloat(vy.value), binaryExpr.Op)
+		return
+	}
+
+	cmp := x.Cmp(y)
+	switch binaryExpr.Op {
+	case token.EQL:
+		v.value = boolValue(cmp == 0)
+	case token.NEQ:
+		v.value = boolValue(cmp != 0)
+	case token.GTR:
+		v.value = boolValue(cmp > 0)
+	case token.GEQ:
+		v.value = boolValue(cmp >= 0)
+	case token.LSS:
+		v.value = boolValue(cmp < 0)
+	case token.LEQ:
+		v.value = boolValue(cmp <= 0)
+	}
+}
+
+// bitwiseDecimal does bitwise operation on decimals without fractional part, the result is a Decimal with zero scale.
+func bitwiseDecimal(v, vx, vy *Visitor, binaryExpr *ast.BinaryExpr) {
+	for _, operand := range [...]*Visitor{vx, vy} {
+		if operand.value.Kind() != KindDecimal {
+			continue
+		}
+		d := operand.value.Decimal()
+		if !d.IsInt() {
+			continue // reported below
+		}
+		operand.value = bigIntValue(d.round(0, RoundDown).int())
+	}
+	if vx.value.Kind() == KindDecimal {
+		v.err = newBitwiseNonIntegerError(vx, binaryExpr.X)
+		return
+	}
+	if vy.value.Kind() == KindDecimal {
+		v.err = newBitwiseNonIntegerError(vy, binaryExpr.Y)
+		return
+	}
+
+	bitwiseBig(v, vx, vy, binaryExpr)
+	if v.err == nil {
+		v.value = decimalValue(Decimal{unscaled: v.value.BigInt()})
+	}
+}
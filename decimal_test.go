@@ -0,0 +1,314 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"math/big"
+	"strconv"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tt := []struct {
+		in            string
+		expectedStr   string
+		expectedScale int
+		expectedErr   error
+	}{
+		{in: "0", expectedStr: "0"},
+		{in: "0.1", expectedStr: "0.1", expectedScale: 1},
+		{in: "-12.50", expectedStr: "-12.50", expectedScale: 2},
+		{in: "+.5", expectedStr: "0.5", expectedScale: 1},
+		{in: "5.", expectedStr: "5"},
+		{in: "1.5e3", expectedStr: "1500"},
+		{in: "25e-4", expectedStr: "0.0025", expectedScale: 4},
+		{in: "-0.001E+2", expectedStr: "-0.1", expectedScale: 1},
+		{in: "123456789012345678901234567890.123456789", expectedStr: "123456789012345678901234567890.123456789", expectedScale: 9},
+		{in: "", expectedErr: errDecimalSyntax},
+		{in: "-", expectedErr: errDecimalSyntax},
+		{in: ".", expectedErr: errDecimalSyntax},
+		{in: "1.2.3", expectedErr: errDecimalSyntax},
+		{in: "1e", expectedErr: errDecimalSyntax},
+		{in: "0x10", expectedErr: errDecimalSyntax},
+		{in: "1_000", expectedErr: errDecimalSyntax},
+		{in: "0e65536", expectedStr: "0"},
+		{in: "1e65537", expectedErr: strconv.ErrRange},
+		{in: "1e-65537", expectedErr: strconv.ErrRange},
+		{in: "1e-99999999999999999999", expectedErr: strconv.ErrRange},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.in, func(t *testing.T) {
+			d, err := ParseDecimal(tc.in)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected err: %v, got: %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr != nil {
+				return
+			}
+			if d.String() != tc.expectedStr {
+				t.Fatalf("expected str: %s, got: %s", tc.expectedStr, d.String())
+			}
+			if d.Scale() != tc.expectedScale {
+				t.Fatalf("expected scale: %d, got: %d", tc.expectedScale, d.Scale())
+			}
+		})
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	var zero Decimal
+	if zero.String() != "0" || zero.Sign() != 0 || zero.Float64() != 0 || !zero.IsInt() {
+		t.Fatalf("expected zero value is 0, got: %s", zero)
+	}
+
+	d := NewDecimal(-1999, 2)
+	if d.String() != "-19.99" {
+		t.Fatalf("expected str: -19.99, got: %s", d)
+	}
+	if d.Float64() != -19.99 {
+		t.Fatalf("expected float64: -19.99, got: %v", d.Float64())
+	}
+	if d.Rat().Cmp(big.NewRat(-1999, 100)) != 0 {
+		t.Fatalf("expected rat: -1999/100, got: %v", d.Rat())
+	}
+	if d.IsInt() {
+		t.Fatalf("expected -19.99 is not an integer")
+	}
+	if s := NewDecimal(2, -3).String(); s != "2000" {
+		t.Fatalf("expected str: 2000, got: %s", s)
+	}
+	if NewDecimal(150, 2).Cmp(NewDecimal(15, 1)) != 0 {
+		t.Fatalf("expected 1.50 == 1.5")
+	}
+	if NewDecimal(-1, 3).Cmp(zero) != -1 {
+		t.Fatalf("expected -0.001 < 0")
+	}
+}
+
+func TestRoundingMode(t *testing.T) {
+	tt := []struct {
+		in       string
+		expected [3]string // RoundHalfEven, RoundHalfUp, RoundDown
+	}{
+		{in: "2.5", expected: [3]string{"2", "3", "2"}},
+		{in: "3.5", expected: [3]string{"4", "4", "3"}},
+		{in: "-2.5", expected: [3]string{"-2", "-3", "-2"}},
+		{in: "2.51", expected: [3]string{"3", "3", "2"}},
+		{in: "-2.49", expected: [3]string{"-2", "-2", "-2"}},
+		{in: "-2.9", expected: [3]string{"-3", "-3", "-2"}},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		for i, mode := range [...]RoundingMode{RoundHalfEven, RoundHalfUp, RoundDown} {
+			mode, expected := mode, tc.expected[i]
+			t.Run(tc.in+" "+mode.String(), func(t *testing.T) {
+				d, err := ParseDecimal(tc.in)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if s := d.round(0, mode).String(); s != expected {
+					t.Fatalf("expected: %s, got: %s", expected, s)
+				}
+			})
+		}
+	}
+
+	if s := RoundingMode(100).String(); s != "RoundingMode(100)" {
+		t.Fatalf("expected: RoundingMode(100), got: %s", s)
+	}
+}
+
+func TestNumericTypeDecimal(t *testing.T) {
+	tt := []struct {
+		in           string
+		options      []Option
+		expectedKind Kind
+		expectedStr  string
+		expectedErr  error
+	}{
+		{in: "10 - 10*0.15", expectedKind: KindDecimal, expectedStr: "8.50"},
+		{in: "0.1 + 0.2", expectedKind: KindDecimal, expectedStr: "0.3"},
+		{in: "0.1 + 0.2 == 0.3", expectedKind: KindBoolean, expectedStr: "true"},
+		{in: "1.50 == 1.5", expectedKind: KindBoolean, expectedStr: "true"},
+		{in: "1.5 < 1.51", expectedKind: KindBoolean, expectedStr: "true"},
+		{in: "7 / 2", expectedKind: KindDecimal, expectedStr: "3.5"},
+		{in: "10 / 4", expectedKind: KindDecimal, expectedStr: "2.5"},
+		{in: "1 / 3", expectedKind: KindDecimal, expectedStr: "0.3333333333333333"},
+		{in: "2 / 3", expectedKind: KindDecimal, expectedStr: "0.6666666666666667"},
+		{in: "2 / 3", options: []Option{WithDecimalScale(2)}, expectedKind: KindDecimal, expectedStr: "0.67"},
+		{in: "2 / 3", options: []Option{WithDecimalScale(2), WithDecimalRounding(RoundDown)}, expectedKind: KindDecimal, expectedStr: "0.66"},
+		{in: "1 / 8", options: []Option{WithDecimalScale(2)}, expectedKind: KindDecimal, expectedStr: "0.12"},
+		{in: "1 / 8", options: []Option{WithDecimalScale(2), WithDecimalRounding(RoundHalfUp)}, expectedKind: KindDecimal, expectedStr: "0.13"},
+		{in: "1 / 8", options: []Option{WithDecimalScale(-1)}, expectedKind: KindDecimal, expectedStr: "0"},
+		{in: "0.125 * 0.5", options: []Option{WithDecimalScale(2)}, expectedKind: KindDecimal, expectedStr: "0.06"},
+		{in: "0.125", options: []Option{WithDecimalScale(2)}, expectedKind: KindDecimal, expectedStr: "0.125"},
+		{in: "7.5 % 2", expectedKind: KindDecimal, expectedStr: "1.5"},
+		{in: "-7.5 % 2", expectedKind: KindDecimal, expectedStr: "-1.5"},
+		{in: "1 / 0", expectedErr: ErrArithmeticOperation},
+		{in: "1 % 0.0", expectedErr: ErrArithmeticOperation},
+		{in: "-(1.25)", expectedKind: KindDecimal, expectedStr: "-1.25"},
+		{in: "1_000.5 + 0x10 + 0o7 + 0b1", expectedKind: KindDecimal, expectedStr: "1024.5"},
+		{in: "0x1p-2", expectedKind: KindDecimal, expectedStr: "0.25"},
+		{in: "017", expectedKind: KindDecimal, expectedStr: "15"}, // legacy octal, the same as Go
+		{in: "017.5", expectedKind: KindDecimal, expectedStr: "17.5"},
+		{in: "017e1", expectedKind: KindDecimal, expectedStr: "170"},
+		{in: "0.5", expectedKind: KindDecimal, expectedStr: "0.5"},
+		{in: "1e-3 * 2", expectedKind: KindDecimal, expectedStr: "0.002"},
+		{in: "99999999999999999999 + 0.01", expectedKind: KindDecimal, expectedStr: "99999999999999999999.01"},
+		{in: "2 * (1+2i)", expectedKind: KindImag, expectedStr: "(2+4i)"},
+		{in: "1.5 == 1.5+0i", expectedKind: KindBoolean, expectedStr: "true"},
+		{in: "0xf0 | 0x0f", expectedKind: KindDecimal, expectedStr: "255"},
+		{in: "4.0 << 2", expectedKind: KindDecimal, expectedStr: "16"},
+		{in: "4.5 << 2", expectedErr: ErrBitwiseOperation},
+		{in: "1 << 4.5", expectedErr: ErrBitwiseOperation},
+		{in: "1 + true", expectedErr: ErrArithmeticOperation},
+	}
+
+	for i, tc := range tt {
+		tc := tc
+		t.Run(fmt.Sprintf("[%d] %s", i, tc.in), func(t *testing.T) {
+			e, err := parser.ParseExpr(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := NewVisitor(append([]Option{WithNumericType(NumericTypeDecimal)}, tc.options...)...)
+			ast.Walk(v, e)
+
+			if err := v.Err(); !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected err: %v, got: %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr != nil {
+				return
+			}
+			if v.Kind() != tc.expectedKind {
+				t.Fatalf("expected kind: %s, got: %s", tc.expectedKind, v.Kind())
+			}
+			if str := fmt.Sprint(v.ValueAny()); str != tc.expectedStr {
+				t.Fatalf("expected val: %s, got: %s", tc.expectedStr, str)
+			}
+		})
+	}
+}
+
+func TestDecimalOperandInOtherNumericTypes(t *testing.T) {
+	price, _ := ParseDecimal("19.99")
+	env := map[string]interface{}{
+		"price": price,
+		"qty":   3,
+		"disc":  0.15,
+		"big":   big.NewInt(1),
+		"nan":   0.0,
+	}
+
+	tt := []struct {
+		in           string
+		numericType  NumericType
+		expectedKind Kind
+		expectedStr  string
+		expectedErr  error
+	}{
+		{in: "price * qty", expectedKind: KindDecimal, expectedStr: "59.97"},
+		{in: "price * qty", numericType: NumericTypeGo, expectedKind: KindDecimal, expectedStr: "59.97"},
+		{in: "price * qty", numericType: NumericTypeBig, expectedKind: KindDecimal, expectedStr: "59.97"},
+		{in: "price - price * disc", expectedKind: KindDecimal, expectedStr: "16.9915"},
+		{in: "price + big", expectedKind: KindDecimal, expectedStr: "20.99"},
+		{in: "price > 19.98", expectedKind: KindBoolean, expectedStr: "true"},
+		{in: "price < 1.0 / nan", expectedKind: KindBoolean, expectedStr: "true"},
+		{in: "price + 1.0 / nan", expectedErr: ErrArithmeticOperation},
+		{in: "price * qty", numericType: NumericTypeFloat, expectedKind: KindFloat, expectedStr: "59.97"},
+		{in: "price * qty", numericType: NumericTypeInt, expectedKind: KindInt, expectedStr: "57"},
+		{in: "-price", expectedKind: KindDecimal, expectedStr: "-19.99"},
+	}
+
+	for i, tc := range tt {
+		tc := tc
+		t.Run(fmt.Sprintf("[%d] %s", i, tc.in), func(t *testing.T) {
+			e, err := parser.ParseExpr(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := NewVisitor(WithNumericType(tc.numericType), WithEnv(env))
+			ast.Walk(v, e)
+
+			if err := v.Err(); !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected err: %v, got: %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr != nil {
+				return
+			}
+			if v.Kind() != tc.expectedKind {
+				t.Fatalf("expected kind: %s, got: %s", tc.expectedKind, v.Kind())
+			}
+			if str := fmt.Sprint(v.ValueAny()); str != tc.expectedStr {
+				t.Fatalf("expected val: %s, got: %s", tc.expectedStr, str)
+			}
+		})
+	}
+}
+
+func TestDecimalWithMath(t *testing.T) {
+	tt := []struct {
+		in          string
+		expectedStr string
+	}{
+		{in: "abs(-1.50)", expectedStr: "1.50"},
+		{in: "abs(1.50)", expectedStr: "1.50"},
+		{in: "max(1.5, 2.25, 2)", expectedStr: "2.25"},
+		{in: "min(1.5, 2.25, 2)", expectedStr: "1.5"},
+		{in: "floor(-2.5)", expectedStr: "-3"},
+		{in: "floor(2.5)", expectedStr: "2"},
+		{in: "ceil(2.1)", expectedStr: "3"},
+		{in: "ceil(-2.1)", expectedStr: "-2"},
+		{in: "trunc(-2.9)", expectedStr: "-2"},
+		{in: "floor(2.0)", expectedStr: "2"},
+		{in: "floor(2)", expectedStr: "2"},
+		{in: "round(2.5)", expectedStr: "3"},
+		{in: "round(-2.5)", expectedStr: "-3"},
+		{in: "round(2.345, 2)", expectedStr: "2.35"},
+		{in: "round(2.345, 5)", expectedStr: "2.345"},
+		{in: "round(1250, -2)", expectedStr: "1300"},
+		{in: "round(-1250.5, -2)", expectedStr: "-1300"},
+		{in: "round(1250, -400)", expectedStr: "0"},
+		{in: "sqrt(2.25)", expectedStr: "1.5"},
+	}
+
+	for i, tc := range tt {
+		tc := tc
+		t.Run(fmt.Sprintf("[%d] %s", i, tc.in), func(t *testing.T) {
+			e, err := parser.ParseExpr(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := NewVisitor(WithNumericType(NumericTypeDecimal), WithMath())
+			ast.Walk(v, e)
+
+			if err := v.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if str := fmt.Sprint(v.ValueAny()); str != tc.expectedStr {
+				t.Fatalf("expected val: %s, got: %s", tc.expectedStr, str)
+			}
+		})
+	}
+}
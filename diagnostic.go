@@ -0,0 +1,210 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"strings"
+	"unicode/utf8"
+)
+
+// Diagnostic describes where an error occurs in the expr string, so it can be shown to the user. Lines and columns
+// start at 1 and columns are counted in bytes like go/scanner does.
+type Diagnostic struct {
+	Msg       string // SyntaxError's Msg.
+	Err       error  // SyntaxError's Err, e.g. ErrArithmeticOperation.
+	Offset    int    // byte offset of the start, starting at 0.
+	End       int    // byte offset immediately after the end, the same as Offset if the error is at the end of the input.
+	Line      int    // line of the start.
+	Column    int    // column of the start.
+	EndLine   int    // line of the end.
+	EndColumn int    // column of the end.
+	Snippet   string // the lines of the expr string from Line to EndLine.
+}
+
+// Diagnose creates Diagnostic for err that is returned by parsing or evaluating the expr string src, e.g. by Compile,
+// Program's Eval or Any. The error is spanned from SyntaxError's Pos until the end of the token at Pos, a parenthesized
+// expression is spanned until its closing parenthesis and an operand until its selector, index or call, e.g. Pos 9 in
+// `price + order.items[0]` is spanned over "order.items[0]". It returns false if err is not a SyntaxError or it has
+// no position in src.
+func Diagnose(src string, err error) (Diagnostic, bool) {
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return Diagnostic{}, false
+	}
+	offset := syntaxErr.Pos - 1 // SyntaxError's Pos is go/parser's position that starts at 1.
+	if offset < 0 || offset > len(src) {
+		return Diagnostic{}, false
+	}
+
+	d := Diagnostic{
+		Msg:    syntaxErr.Msg,
+		Err:    syntaxErr.Err,
+		Offset: offset,
+		End:    spanEnd(src, offset),
+	}
+	d.Line, d.Column = lineColumn(src, d.Offset)
+	d.EndLine, d.EndColumn = lineColumn(src, d.End)
+
+	begin := strings.LastIndexByte(src[:d.Offset], '\n') + 1
+	end := strings.IndexByte(src[d.End:], '\n')
+	if end == -1 {
+		end = len(src)
+	} else {
+		end += d.End
+	}
+	d.Snippet = src[begin:end]
+
+	return d, true
+}
+
+// Error returns the error in the same format as go/scanner's, e.g. `1:9: could not concatenate ...: arithmetic operation`.
+func (d Diagnostic) Error() string {
+	return fmt.Sprintf("%d:%d: %s: %v", d.Line, d.Column, d.Msg, d.Err)
+}
+
+// Format formats d with its Snippet and a caret underline beneath the error, e.g:
+//
+//	1:9: could not concatenate: result of "order.items[0]" is "1" which is not a string: arithmetic operation
+//	"id-" + order.items[0]
+//	        ^^^^^^^^^^^^^^
+func (d Diagnostic) Format() string {
+	var sb strings.Builder
+	sb.WriteString(d.Error())
+
+	lines := strings.Split(d.Snippet, "\n")
+	for i, line := range lines {
+		sb.WriteString("\n")
+		sb.WriteString(line)
+		sb.WriteString("\n")
+
+		from, to := 0, len(line) // byte offsets of the underline in the line.
+		if i == 0 {
+			from = d.Column - 1
+		}
+		if i == d.EndLine-d.Line {
+			to = d.EndColumn - 1
+		}
+		if from > len(line) {
+			from = len(line)
+		}
+		if to > len(line) {
+			to = len(line)
+		}
+
+		for _, r := range line[:from] { // keep the tabs so the caret is aligned with the line.
+			if r == '\t' {
+				sb.WriteByte('\t')
+			} else {
+				sb.WriteByte(' ')
+			}
+		}
+		n := utf8.RuneCountInString(line[from:to])
+		if n == 0 {
+			n = 1
+		}
+		sb.WriteString(strings.Repeat("^", n))
+	}
+	return sb.String()
+}
+
+// lineColumn returns the line and the column of offset in src.
+func lineColumn(src string, offset int) (line, column int) {
+	line = strings.Count(src[:offset], "\n") + 1
+	column = offset - (strings.LastIndexByte(src[:offset], '\n') + 1) + 1
+	return line, column
+}
+
+// spanEnd returns the end offset of the token at offset, see Diagnose.
+func spanEnd(src string, offset int) int {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+
+	var s scanner.Scanner
+	s.Init(file, []byte(src), nil, 0)
+
+	type scanned struct {
+		offset, end int
+		tok         token.Token
+	}
+	scan := func() scanned {
+		for {
+			pos, tok, lit := s.Scan()
+			if tok == token.SEMICOLON && lit == "\n" { // automatically inserted
+				continue
+			}
+			offset := file.Offset(pos)
+			end := offset + len(tok.String())
+			if lit != "" {
+				end = offset + len(lit)
+			}
+			if tok == token.EOF {
+				end = offset
+			}
+			return scanned{offset: offset, end: end, tok: tok}
+		}
+	}
+
+	var t scanned
+	for t = scan(); t.tok != token.EOF && t.offset < offset; t = scan() {
+	}
+	if t.offset != offset || t.tok == token.EOF {
+		return offset
+	}
+
+	// skipGroup skips the tokens until the closing one of the opening t, it returns the last scanned token.
+	skipGroup := func(t scanned) scanned {
+		depth := 0
+		for ; t.tok != token.EOF; t = scan() {
+			switch t.tok {
+			case token.LPAREN, token.LBRACK, token.LBRACE:
+				depth++
+			case token.RPAREN, token.RBRACK, token.RBRACE:
+				depth--
+			}
+			if depth == 0 {
+				break
+			}
+		}
+		return t
+	}
+
+	switch {
+	case t.tok == token.LPAREN:
+		t = skipGroup(t)
+	case t.tok.IsLiteral(): // including identifier
+	default:
+		return t.end // operator
+	}
+	end := t.end
+
+	for t = scan(); ; t = scan() { // selector, index or call.
+		switch t.tok {
+		case token.PERIOD:
+			if t = scan(); t.tok != token.IDENT {
+				return end
+			}
+		case token.LPAREN, token.LBRACK, token.LBRACE:
+			if t = skipGroup(t); t.tok == token.EOF {
+				return end
+			}
+		default:
+			return end
+		}
+		end = t.end
+	}
+}
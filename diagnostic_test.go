@@ -0,0 +1,144 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/muktihari/expr"
+)
+
+func TestDiagnose(t *testing.T) {
+	env := expr.Env{"order": map[string]interface{}{"items": []int{1}}, "name": "a", "price": 2.0}
+
+	tt := []struct {
+		in                string
+		options           []expr.Option
+		expectedOffset    int
+		expectedEnd       int
+		expectedLine      int
+		expectedColumn    int
+		expectedEndLine   int
+		expectedEndColumn int
+		expectedFormat    string
+	}{
+		{
+			in:             `"id-" + order.items[0]`,
+			expectedOffset: 8, expectedEnd: 22,
+			expectedLine: 1, expectedColumn: 9, expectedEndLine: 1, expectedEndColumn: 23,
+			expectedFormat: `1:9: could not concatenate: result of "order.items[0]" is "1" which is not a string: arithmetic operation` + "\n" +
+				`"id-" + order.items[0]` + "\n" +
+				`        ^^^^^^^^^^^^^^`,
+		},
+		{
+			in:             "price > 1 &&\n\tname",
+			expectedOffset: 14, expectedEnd: 18,
+			expectedLine: 2, expectedColumn: 2, expectedEndLine: 2, expectedEndColumn: 6,
+			expectedFormat: `2:2: result of "name" is "a" which is not a boolean: logical operation` + "\n" +
+				"\tname\n" +
+				"\t^^^^",
+		},
+		{
+			in:             "price == 1 ||\n  (2 * 3",
+			expectedOffset: 22, expectedEnd: 22,
+			expectedLine: 2, expectedColumn: 9, expectedEndLine: 2, expectedEndColumn: 9,
+			expectedFormat: "2:9: expected ')', found newline: invalid syntax\n" +
+				"  (2 * 3\n" +
+				"        ^",
+		},
+		{
+			in:             "price +",
+			options:        []expr.Option{expr.WithFastParser(true)},
+			expectedOffset: 7, expectedEnd: 7,
+			expectedLine: 1, expectedColumn: 8, expectedEndLine: 1, expectedEndColumn: 8,
+			expectedFormat: "1:8: expected operand, found 'EOF': invalid syntax\n" +
+				"price +\n" +
+				"       ^",
+		},
+		{
+			in:             "héllo + 1",
+			expectedOffset: 9, expectedEnd: 10,
+			expectedLine: 1, expectedColumn: 10, expectedEndLine: 1, expectedEndColumn: 11,
+			expectedFormat: `1:10: could not concatenate: result of "1" is "1" which is not a string: arithmetic operation` + "\n" +
+				"héllo + 1\n" +
+				"        ^",
+		},
+		{
+			in:             "1 + tier(price,\n  2) * 3",
+			expectedOffset: 4, expectedEnd: 20,
+			expectedLine: 1, expectedColumn: 5, expectedEndLine: 2, expectedEndColumn: 5,
+			expectedFormat: `1:5: function "tier" is undefined: function call` + "\n" +
+				"1 + tier(price,\n" +
+				"    ^^^^^^^^^^^\n" +
+				"  2) * 3\n" +
+				"^^^^",
+		},
+		{
+			in:             "1 + ^price",
+			expectedOffset: 4, expectedEnd: 5,
+			expectedLine: 1, expectedColumn: 5, expectedEndLine: 1, expectedEndColumn: 6,
+			expectedFormat: `1:5: operator "^" is unsupported: unsupported operator` + "\n" +
+				"1 + ^price\n" +
+				"    ^",
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.in, func(t *testing.T) {
+			p, err := expr.Compile(tc.in, tc.options...)
+			if err == nil {
+				_, err = p.Eval(env)
+			}
+
+			d, ok := expr.Diagnose(tc.in, err)
+			if !ok {
+				t.Fatalf("expected ok, got: %v", err)
+			}
+			if d.Offset != tc.expectedOffset || d.End != tc.expectedEnd {
+				t.Fatalf("expected offset: [%d:%d], got: [%d:%d]", tc.expectedOffset, tc.expectedEnd, d.Offset, d.End)
+			}
+			if d.Line != tc.expectedLine || d.Column != tc.expectedColumn {
+				t.Fatalf("expected start: %d:%d, got: %d:%d", tc.expectedLine, tc.expectedColumn, d.Line, d.Column)
+			}
+			if d.EndLine != tc.expectedEndLine || d.EndColumn != tc.expectedEndColumn {
+				t.Fatalf("expected end: %d:%d, got: %d:%d", tc.expectedEndLine, tc.expectedEndColumn, d.EndLine, d.EndColumn)
+			}
+			if !errors.Is(err, d.Err) {
+				t.Fatalf("expected err: %v, got: %v", err, d.Err)
+			}
+			if tc.expectedFormat != "" && d.Format() != tc.expectedFormat {
+				t.Fatalf("expected format:\n%s\ngot:\n%s", tc.expectedFormat, d.Format())
+			}
+		})
+	}
+
+	t.Run("one-shot", func(t *testing.T) {
+		_, err := expr.Any("1 + (2 == 3)")
+		d, ok := expr.Diagnose("1 + (2 == 3)", err)
+		if !ok || d.Offset != 5 || d.End != 6 {
+			t.Fatalf("expected [5:6], got: %t [%d:%d]", ok, d.Offset, d.End)
+		}
+	})
+
+	t.Run("no position", func(t *testing.T) {
+		if _, ok := expr.Diagnose("1", errors.New("err")); ok {
+			t.Fatalf("expected not ok")
+		}
+		if _, ok := expr.Diagnose("1", &expr.SyntaxError{Pos: 10, Err: expr.ErrUnsupportedSyntax}); ok {
+			t.Fatalf("expected not ok")
+		}
+	})
+}
@@ -0,0 +1,112 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"math/big"
+	"reflect"
+)
+
+// Resolver resolves an identifier's name in expr string into its value.
+// The resolved value should be one of Go's primitive types: bool, string, integer, float or complex,
+// or one of *big.Int, *big.Float, *big.Rat (converted into *big.Float) and Decimal.
+// Map, slice, array, struct or pointer to them is also accepted, its elements can be accessed using selector
+// and index expression: order.customer.tier, items[0].price, labels["env"]. Nil, nil pointer, nil map and nil slice
+// are resolved as nil: coupon == nil.
+type Resolver interface {
+	// Resolve returns the value of given name and whether the name is found.
+	Resolve(name string) (interface{}, bool)
+}
+
+// Env is a map implementation of Resolver.
+type Env map[string]interface{}
+
+var _ Resolver = Env(nil)
+
+// Resolve returns the value of given name and whether the name is found.
+func (e Env) Resolve(name string) (interface{}, bool) {
+	val, ok := e[name]
+	return val, ok
+}
+
+// valueOf creates value from Go's primitive types, it returns false if x's type is not supported. Nil, including nil
+// pointer, map and slice, is a nil value.
+func valueOf(x interface{}) (value, bool) {
+	// declared common used types for faster conversion
+	switch val := x.(type) {
+	case bool:
+		return boolValue(val), true
+	case int:
+		return int64Value(int64(val)), true
+	case int64:
+		return int64Value(val), true
+	case float64:
+		return float64Value(val), true
+	case complex128:
+		return complex128Value(val), true
+	case string:
+		return stringValue(val), true
+	case *big.Int:
+		if val == nil {
+			return nilValue(), true
+		}
+		return bigIntValue(val), true
+	case *big.Float:
+		if val == nil {
+			return nilValue(), true
+		}
+		return bigFloatValue(val), true
+	case *big.Rat:
+		if val == nil {
+			return nilValue(), true
+		}
+		return bigFloatValue(new(big.Float).SetPrec(DefaultBigFloatPrecision).SetRat(val)), true
+	case Decimal:
+		return decimalValue(val), true
+	}
+
+	if x == nil {
+		return nilValue(), true
+	}
+
+	rv := reflect.ValueOf(x) // slower but it can handle the remaining basic types including named types.
+	switch rv.Kind() {
+	case reflect.Bool:
+		return boolValue(rv.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int64Value(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return uint64Value(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return float64Value(rv.Float()), true
+	case reflect.Complex64, reflect.Complex128:
+		return complex128Value(rv.Complex()), true
+	case reflect.String:
+		return stringValue(rv.String()), true
+	case reflect.Map, reflect.Slice:
+		if rv.IsNil() {
+			return nilValue(), true
+		}
+		return objectValue(x), true
+	case reflect.Array, reflect.Struct:
+		return objectValue(x), true
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nilValue(), true
+		}
+		return valueOf(rv.Elem().Interface())
+	}
+
+	return value{}, false
+}
@@ -0,0 +1,86 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestValueOf(t *testing.T) {
+	type money float64
+	type status string
+
+	tt := []struct {
+		in            interface{}
+		expectedValue value
+		expectedOk    bool
+	}{
+		{in: nil, expectedValue: nilValue(), expectedOk: true},
+		{in: true, expectedValue: boolValue(true), expectedOk: true},
+		{in: int(1), expectedValue: int64Value(1), expectedOk: true},
+		{in: int8(-2), expectedValue: int64Value(-2), expectedOk: true},
+		{in: int32(3), expectedValue: int64Value(3), expectedOk: true},
+		{in: int64(4), expectedValue: int64Value(4), expectedOk: true},
+		{in: uint8(5), expectedValue: uint64Value(5), expectedOk: true},
+		{in: uint64(math.MaxUint64), expectedValue: uint64Value(math.MaxUint64), expectedOk: true},
+		{in: float32(0.5), expectedValue: float64Value(0.5), expectedOk: true},
+		{in: float64(0.15), expectedValue: float64Value(0.15), expectedOk: true},
+		{in: complex64(1 + 2i), expectedValue: complex128Value(1 + 2i), expectedOk: true},
+		{in: complex128(1 + 2i), expectedValue: complex128Value(1 + 2i), expectedOk: true},
+		{in: "abc", expectedValue: stringValue("abc"), expectedOk: true},
+		{in: money(10.5), expectedValue: float64Value(10.5), expectedOk: true},
+		{in: status("active"), expectedValue: stringValue("active"), expectedOk: true},
+		{in: []int{1}, expectedValue: objectValue([]int{1}), expectedOk: true},
+		{in: [1]int{1}, expectedValue: objectValue([1]int{1}), expectedOk: true},
+		{in: map[string]int{"a": 1}, expectedValue: objectValue(map[string]int{"a": 1}), expectedOk: true},
+		{in: struct{}{}, expectedValue: objectValue(struct{}{}), expectedOk: true},
+		{in: &struct{ A int }{A: 1}, expectedValue: objectValue(struct{ A int }{A: 1}), expectedOk: true},
+		{in: new(int), expectedValue: int64Value(0), expectedOk: true},
+		{in: []int(nil), expectedValue: nilValue(), expectedOk: true},
+		{in: (*int)(nil), expectedValue: nilValue(), expectedOk: true},
+		{in: (*big.Int)(nil), expectedValue: nilValue(), expectedOk: true},
+		{in: make(chan int), expectedValue: value{}, expectedOk: false},
+	}
+
+	for i, tc := range tt {
+		tc := tc
+		t.Run(fmt.Sprintf("[%d] %T", i, tc.in), func(t *testing.T) {
+			val, ok := valueOf(tc.in)
+			if ok != tc.expectedOk {
+				t.Fatalf("expected ok: %t, got: %t", tc.expectedOk, ok)
+			}
+			if val.Kind() != tc.expectedValue.Kind() {
+				t.Fatalf("expected kind: %s, got: %s", tc.expectedValue.Kind(), val.Kind())
+			}
+			if !reflect.DeepEqual(val.Any(), tc.expectedValue.Any()) {
+				t.Fatalf("expected value: %v (%T), got: %v (%T)", tc.expectedValue.Any(), tc.expectedValue.Any(), val.Any(), val.Any())
+			}
+		})
+	}
+}
+
+func TestEnvResolve(t *testing.T) {
+	env := Env{"price": 10.0}
+
+	if val, ok := env.Resolve("price"); !ok || val != 10.0 {
+		t.Fatalf("expected: (%v, %t), got: (%v, %t)", 10.0, true, val, ok)
+	}
+	if val, ok := env.Resolve("discount"); ok || val != nil {
+		t.Fatalf("expected: (%v, %t), got: (%v, %t)", nil, false, val, ok)
+	}
+}
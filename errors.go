@@ -28,12 +28,32 @@ var (
 	// ErrIntegerDividedByZero occurs when x/y and y equals to 0 and AllowIntDivByZero == false (default).
 	// Go does not allow integer to be divided by zero by default.
 	ErrIntegerDividedByZero = errors.New("integer divided by zero")
+	// ErrIntegerOverflow occurs when integer operation overflows int64 and OverflowError policy is used.
+	ErrIntegerOverflow = errors.New("integer overflow")
 	// ErrInvalidBitwiseOperation occurs when neither x nor y is an int
 	ErrBitwiseOperation = errors.New("bitwise operation")
 	// ErrBitwiseOperation occurs when either x or y is boolean and given operator is neither '==' nor '!='
 	ErrComparisonOperation = errors.New("comparison operation")
 	// ErrLogicalOperation occurs when either x or y is not boolean
 	ErrLogicalOperation = errors.New("logical operation")
+	// ErrFunctionCall occurs when function is undefined or it is called with invalid arguments
+	ErrFunctionCall = errors.New("function call")
+	// ErrUnsupportedVariableType occurs when the resolved variable's value is not one of Go's primitive types
+	ErrUnsupportedVariableType = errors.New("unsupported variable type")
+	// ErrIndexOperation occurs when the value is not indexable (or has no fields) or the index is invalid
+	ErrIndexOperation = errors.New("index operation")
+	// ErrIndexOutOfRange occurs when the index is out of the indexed value's range
+	ErrIndexOutOfRange = errors.New("index out of range")
+	// ErrMissingField occurs when field or map key is not found and WithMissingAsNil is not used
+	ErrMissingField = errors.New("missing field")
+	// ErrUnknownIdentifier occurs when identifier is neither true, false nor resolved and WithStrictIdents is used
+	ErrUnknownIdentifier = errors.New("unknown identifier")
+	// ErrInvalidSyntax occurs when expr string is not a valid expression, e.g. "1 +"
+	ErrInvalidSyntax = errors.New("invalid syntax")
+	// ErrUnsupportedSyntax occurs when expr string contains Go syntax that is not supported, e.g. "foo.bar"
+	ErrUnsupportedSyntax = errors.New("unsupported syntax")
+	// ErrLimitExceeded occurs when the expression exceeds one of the limits, e.g. WithMaxDepth or WithMaxNodes
+	ErrLimitExceeded = errors.New("limit exceeded")
 	// ErrValueTypeMismatch occurs when the result of expr evaluation is not match with desired type
 	ErrValueTypeMismatch = errors.New("returned value's type is not match with desired type")
 )
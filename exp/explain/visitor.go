@@ -166,6 +166,12 @@ func (v *Visitor) Visit(node ast.Node) ast.Visitor {
 		v.value, v.exprType = d.Value, basicLit
 	case *ast.Ident:
 		v.value, v.exprType = d.Name, ident
+	default:
+		v.err = &expr.SyntaxError{
+			Msg: conv.DescribeSyntax(node) + " is not supported",
+			Pos: int(node.Pos()),
+			Err: expr.ErrUnsupportedSyntax,
+		}
 	}
 
 	return nil
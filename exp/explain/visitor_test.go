@@ -99,6 +99,26 @@ func TestVisit(t *testing.T) {
 			str: "1.2 & 1",
 			err: expr.ErrBitwiseOperation,
 		},
+		{
+			str: "foo.bar",
+			err: expr.ErrUnsupportedSyntax,
+		},
+		{
+			str: "1 + []int{1}",
+			err: expr.ErrUnsupportedSyntax,
+		},
+		{
+			str: "!x.(bool)",
+			err: expr.ErrUnsupportedSyntax,
+		},
+		{
+			str: "abs(-1) > 0",
+			err: expr.ErrUnsupportedSyntax,
+		},
+		{
+			str: "\"abcdef\"[1:3] + \"abc\"[0:1]",
+			err: expr.ErrUnsupportedSyntax,
+		},
 		{
 			str: "4 << 10",
 			transforms: []Transform{
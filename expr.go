@@ -14,7 +14,7 @@
 package expr
 
 import (
-	"go/parser"
+	"go/ast"
 )
 
 // Any parses the given expr string into any type it returns as a result. e.g:
@@ -32,38 +32,20 @@ import (
 //   - Logical: [&&, ||, !]
 //   - Arithmetic: [+, -, *, /, %] (% operator does not work for complex number)
 //   - Bitwise: [&, |, ^, &^, <<, >>] (only work for integer values)
+//
+// Any and the other one-shot functions (Bool, Complex128, Float64, Int64, Int64Strict, Int and Uint64) take no Option,
+// so their evaluation is never limited nor canceled. Use Compile with WithMaxInputLen, WithMaxDepth, WithMaxNodes and
+// WithMaxStringLen and then Program's EvalContext to evaluate an untrusted expr string.
 func Any(str string) (interface{}, error) {
-	expr, err := parser.ParseExpr(str)
-	if err != nil {
-		return nil, err
-	}
+	o := defaultOptions()
+	o.allowIntegerDividedByZero = true
+	o.numericType = NumericTypeAuto
 
-	var v Visitor
-	v.options = defaultOptions()
-	v.options.allowIntegerDividedByZero = true
-	v.options.numericType = NumericTypeAuto
-
-	v.Visit(expr)
-	if err := v.Err(); err != nil {
+	val, err := eval(str, o)
+	if err != nil {
 		return nil, err
 	}
-
-	switch v.value.Kind() {
-	case KindBoolean:
-		return v.value.Bool(), nil
-	case KindInt:
-		return v.value.Int64(), nil
-	case KindFloat:
-		val := v.value.Float64()
-		if val == float64(int64(val)) {
-			return int64(val), nil
-		}
-		return val, nil
-	case KindImag:
-		return v.value.Complex128(), nil
-	default:
-		return v.value.String(), nil
-	}
+	return valueAsAny(val, o.numericType), nil
 }
 
 // Bool parses the given expr string into boolean as a result. e.g:
@@ -82,24 +64,11 @@ func Any(str string) (interface{}, error) {
 //   - Arithmetic: [+, -, *, /, %] (% operator does not work for complex number)
 //   - Bitwise: [&, |, ^, &^, <<, >>] (only work for integer values)
 func Bool(str string) (bool, error) {
-	expr, err := parser.ParseExpr(str)
+	val, err := eval(str, defaultOptions())
 	if err != nil {
 		return false, err
 	}
-
-	var v Visitor
-	v.options = defaultOptions()
-
-	v.Visit(expr)
-	if err := v.Err(); err != nil {
-		return false, err
-	}
-
-	if v.value.Kind() == KindBoolean {
-		return v.value.Bool(), nil
-	}
-
-	return false, ErrValueTypeMismatch
+	return valueAsBool(val)
 }
 
 // Complex128 parses the given expr string into complex128 as a result. e.g:
@@ -110,30 +79,14 @@ func Bool(str string) (bool, error) {
 // - Supported operators:
 //   - Arithmetic: [+, -, *, /]
 func Complex128(str string) (complex128, error) {
-	expr, err := parser.ParseExpr(str)
-	if err != nil {
-		return 0, err
-	}
-
-	var v Visitor
-	v.options = defaultOptions()
-	v.options.numericType = NumericTypeComplex
+	o := defaultOptions()
+	o.numericType = NumericTypeComplex
 
-	v.Visit(expr)
-	if err := v.Err(); err != nil {
+	val, err := eval(str, o)
+	if err != nil {
 		return 0, err
 	}
-
-	switch v.value.Kind() {
-	case KindImag:
-		return v.value.Complex128(), nil
-	case KindFloat:
-		return complex(v.value.Float64(), 0), nil
-	case KindInt:
-		return complex(float64(v.value.Int64()), 0), nil
-	}
-
-	return 0, ErrValueTypeMismatch
+	return valueAsComplex128(val)
 }
 
 // Float64 parses the given expr string into float64 as a result. e.g:
@@ -145,30 +98,14 @@ func Complex128(str string) (complex128, error) {
 // - Supported operators:
 //   - Arithmetic: [+, -, *, /, %]
 func Float64(str string) (float64, error) {
-	expr, err := parser.ParseExpr(str)
-	if err != nil {
-		return 0, err
-	}
-
-	var v Visitor
-	v.options = defaultOptions()
-	v.options.numericType = NumericTypeFloat
+	o := defaultOptions()
+	o.numericType = NumericTypeFloat
 
-	v.Visit(expr)
-	if err := v.Err(); err != nil {
+	val, err := eval(str, o)
+	if err != nil {
 		return 0, err
 	}
-
-	switch v.value.Kind() {
-	case KindImag:
-		return real(v.value.Complex128()), nil
-	case KindFloat:
-		return v.value.Float64(), nil
-	case KindInt:
-		return float64(v.value.Int64()), nil
-	}
-
-	return 0, ErrValueTypeMismatch
+	return valueAsFloat64(val)
 }
 
 // - Int64 parses the given expr string into int64 as a result. e.g:
@@ -197,30 +134,165 @@ func Int(str string) (int, error) {
 	return int(v), nil
 }
 
+// Uint64 parses the given expr string into uint64 as a result, every number is treated as uint64
+// and negative result wraps around like Go. e.g:
+//   - "0xFFFFFFFFFFFFFFFF & 0xFF00" -> 65280
+//   - "1 << 63 >> 62" -> 2
+//   - "0 - 1" -> 18446744073709551615
+//
+// - Supported operators:
+//   - Arithmetic: [+, -, *, /, %]
+//   - Bitwise: [&, |, ^, &^, <<, >>]
+func Uint64(str string) (uint64, error) {
+	o := defaultOptions()
+	o.numericType = NumericTypeUint
+
+	val, err := eval(str, o)
+	if err != nil {
+		return 0, err
+	}
+	return valueAsUint64(val)
+}
+
 func parseStringExprIntoInt64(str string, allowIntegerDividedByZero bool) (int64, error) {
-	expr, err := parser.ParseExpr(str)
+	o := defaultOptions()
+	o.allowIntegerDividedByZero = allowIntegerDividedByZero
+	o.numericType = NumericTypeInt
+
+	val, err := eval(str, o)
 	if err != nil {
 		return 0, err
 	}
+	return valueAsInt64(val)
+}
+
+// eval parses str and evaluates it using given options.
+func eval(str string, o options) (value, error) {
+	e, err := parseExpr(str, &o)
+	if err != nil {
+		return value{}, err
+	}
+	return visit(e, o)
+}
 
-	var v Visitor
-	v.options = defaultOptions()
-	v.options.allowIntegerDividedByZero = allowIntegerDividedByZero
-	v.options.numericType = NumericTypeInt
+// visit evaluates the parsed expression e using given options.
+func visit(e ast.Expr, o options) (value, error) {
+	v := pool.Get().(*Visitor)
+	defer pool.Put(v)
+	v.reset(o)
 
-	v.Visit(expr)
+	v.Visit(e)
 	if err := v.Err(); err != nil {
-		return 0, err
+		return value{}, err
 	}
+	return v.value, nil
+}
 
-	switch v.value.Kind() {
+// valueAsAny converts val into Go's primitive type, float without decimal will be converted into int64
+// only if numericType is NumericTypeAuto since it always calculates using float.
+func valueAsAny(val value, numericType NumericType) interface{} {
+	switch val.Kind() {
+	case KindBoolean:
+		return val.Bool()
+	case KindInt:
+		return val.Int64()
+	case KindUint:
+		return val.Uint64()
+	case KindRune:
+		return rune(val.Int64())
+	case KindFloat:
+		f := val.Float64()
+		if numericType == NumericTypeAuto && f == float64(int64(f)) {
+			return int64(f)
+		}
+		return f
 	case KindImag:
-		return int64(real(v.value.Complex128())), nil
+		return val.Complex128()
+	case KindBigInt:
+		return val.BigInt()
+	case KindBigFloat:
+		return val.BigFloat()
+	case KindDecimal:
+		return val.Decimal()
+	case KindObject:
+		return val.Object()
+	case KindList:
+		return val.Any()
+	case KindNil:
+		return nil
+	default:
+		return val.String()
+	}
+}
+
+func valueAsBool(val value) (bool, error) {
+	if val.Kind() == KindBoolean {
+		return val.Bool(), nil
+	}
+	return false, ErrValueTypeMismatch
+}
+
+func valueAsComplex128(val value) (complex128, error) {
+	switch val.Kind() {
+	case KindImag:
+		return val.Complex128(), nil
 	case KindFloat:
-		return int64(v.value.Float64()), nil
-	case KindInt:
-		return v.value.Int64(), nil
+		return complex(val.Float64(), 0), nil
+	case KindInt, KindRune:
+		return complex(float64(val.Int64()), 0), nil
+	case KindBigInt, KindBigFloat, KindDecimal, KindUint:
+		return parseComplex(val), nil
 	}
+	return 0, ErrValueTypeMismatch
+}
+
+func valueAsFloat64(val value) (float64, error) {
+	switch val.Kind() {
+	case KindImag:
+		return real(val.Complex128()), nil
+	case KindFloat:
+		return val.Float64(), nil
+	case KindInt, KindRune:
+		return float64(val.Int64()), nil
+	case KindBigInt, KindBigFloat, KindDecimal, KindUint:
+		return parseFloat(val), nil
+	}
+	return 0, ErrValueTypeMismatch
+}
 
+func valueAsUint64(val value) (uint64, error) {
+	switch val.Kind() {
+	case KindUint:
+		return val.Uint64(), nil
+	case KindInt, KindRune, KindFloat, KindImag, KindBigInt, KindBigFloat, KindDecimal:
+		if parseFloat(val) < 0 {
+			return 0, ErrValueTypeMismatch
+		}
+		return parseUint(val), nil
+	}
+	return 0, ErrValueTypeMismatch
+}
+
+func valueAsDecimal(val value) (Decimal, error) {
+	if val.Kind() == KindImag {
+		val = float64Value(real(val.Complex128()))
+	}
+	if d, ok := parseDecimal(val); ok {
+		return d, nil
+	}
+	return Decimal{}, ErrValueTypeMismatch
+}
+
+func valueAsInt64(val value) (int64, error) {
+	switch val.Kind() {
+	case KindImag:
+		return int64(real(val.Complex128())), nil
+	case KindFloat:
+		return int64(val.Float64()), nil
+	case KindInt, KindRune:
+		return val.Int64(), nil
+	case KindBigInt, KindBigFloat, KindDecimal, KindUint:
+		return parseInt(val), nil
+	}
 	return 0, ErrValueTypeMismatch
 }
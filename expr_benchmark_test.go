@@ -150,3 +150,69 @@ func BenchmarkInt64(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkProgramEval(b *testing.B) {
+	var (
+		e string = exprs[expr.KindInt]
+		r int64  = 23
+	)
+
+	p, err := expr.Compile(e)
+	if err != nil {
+		b.Fatalf("expected nil, got: %v", err)
+	}
+
+	b.Run(expr.KindInt.String(), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			v, err := p.Eval(nil)
+			if err != nil {
+				b.Fatalf("expected nil, got: %v", err)
+			}
+			if v != r {
+				b.Fatalf("expected value: %d, got: %v", r, v)
+			}
+		}
+	})
+}
+
+func BenchmarkProgramEvalWithEnv(b *testing.B) {
+	p, err := expr.Compile("price - (price * discountPercentage)")
+	if err != nil {
+		b.Fatalf("expected nil, got: %v", err)
+	}
+	env := expr.Env{
+		"price":              10.0,
+		"discountPercentage": 0.15,
+	}
+
+	for i := 0; i < b.N; i++ {
+		v, err := p.Eval(env)
+		if err != nil {
+			b.Fatalf("expected nil, got: %v", err)
+		}
+		if expected := float64(8.5); v != expected {
+			b.Fatalf("expected value: %v, got: %v", expected, v)
+		}
+	}
+}
+
+func BenchmarkCompile(b *testing.B) {
+	e := exprs[expr.KindBoolean]
+
+	b.Run("go/parser", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := expr.Compile(e); err != nil {
+				b.Fatalf("expected nil, got: %v", err)
+			}
+		}
+	})
+	b.Run("FastParser", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := expr.Compile(e, expr.WithFastParser(true)); err != nil {
+				b.Fatalf("expected nil, got: %v", err)
+			}
+		}
+	})
+}
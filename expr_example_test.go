@@ -50,7 +50,7 @@ func ExampleAny() {
 	// (1+0i) <nil>
 	// 0 <nil>
 	// <nil> result of "(4 == 2)" is "false" which is not a number [pos: 10]: arithmetic operation
-	// <nil> 1:7: expected ')', found newline
+	// <nil> expected ')', found newline [pos: 7]: invalid syntax
 }
 
 func ExampleBool() {
@@ -84,7 +84,7 @@ func ExampleBool() {
 	// false <nil>
 	// true <nil>
 	// true <nil>
-	// false 1:8: expected operand, found 'EOF'
+	// false expected operand, found 'EOF' [pos: 8]: invalid syntax
 	// true <nil>
 	// true <nil>
 	// true <nil>
@@ -122,7 +122,7 @@ func ExampleComplex128() {
 	// (1+0i) <nil>
 	// (0+0i) operator "%" is not supported to do arithmetic on complex number [pos: 3]: arithmetic operation
 	// (0+0i) result of "(4 == 2)" is "false" which is not a number [pos: 10]: arithmetic operation
-	// (0+0i) 1:7: expected ')', found newline
+	// (0+0i) expected ')', found newline [pos: 7]: invalid syntax
 }
 
 func ExampleFloat64() {
@@ -156,7 +156,7 @@ func ExampleFloat64() {
 	// 466.2567 <nil>
 	// 0 <nil>
 	// 0 result of "(4 == 2)" is "false" which is not a number [pos: 10]: arithmetic operation
-	// 0 1:7: expected ')', found newline
+	// 0 expected ')', found newline [pos: 7]: invalid syntax
 }
 
 func ExampleInt() {
@@ -208,7 +208,7 @@ func ExampleInt64() {
 	// 440 <nil>
 	// 3 <nil>
 	// 0 result of "(4 == 2)" is "false" which is not a number [pos: 10]: arithmetic operation
-	// 0 1:8: expected 'EOF', found ')'
+	// 0 expected 'EOF', found ')' [pos: 8]: invalid syntax
 }
 
 func ExampleInt64Strict() {
@@ -16,6 +16,7 @@ package expr_test
 import (
 	"errors"
 	"math"
+	"strconv"
 	"testing"
 
 	"github.com/muktihari/expr"
@@ -66,6 +67,26 @@ func TestAny(t *testing.T) {
 		{In: "(2+3i) - (2+2i)", Eq: complex(0, 1)},
 		{In: "(2+2i) * (2+2i)", Eq: complex(0, 8)},
 		{In: "(2+2i) / (2+2i)", Eq: complex(1, 0)},
+		{In: `"a\tb"`, Eq: "a\tb"},
+		{In: `"\"quoted\""`, Eq: `"quoted"`},
+		{In: `"\u00e9"`, Eq: "é"},
+		{In: "`raw\\t`", Eq: `raw\t`},
+		{In: "'a'", Eq: 'a'},
+		{In: `'\n'`, Eq: '\n'},
+		{In: "'é'", Eq: 'é'},
+		{In: "'a' + 1", Eq: int64(98)},
+		{In: "'a' + 0.5", Eq: float64(97.5)},
+		{In: "-'a'", Eq: int64(-97)},
+		{In: "'a' | 0x20", Eq: int64(97)},
+		{In: "'a' + \"b\"", Err: expr.ErrArithmeticOperation},
+		{In: "foo.bar", Err: expr.ErrIndexOperation},
+		{In: "*foo", Err: expr.ErrUnsupportedSyntax},
+		{In: "nil", Eq: nil},
+		{In: "[]int{1}[0]", Eq: int64(1)},
+		{In: "map[string]int{}", Err: expr.ErrUnsupportedSyntax},
+		{In: "-9223372036854775808", Eq: int64(math.MinInt64)},
+		{In: "-0xFFFFFFFFFFFFFFFF", Eq: uint64(1)}, // wraps around like Go's uint64
+		{In: "1 - 9223372036854775808", Eq: int64(-9223372036854775807)},
 	}
 
 	for _, tc := range tt {
@@ -107,6 +128,12 @@ func TestBool(t *testing.T) {
 		{In: "(\"expr\" == \"expr\" && \"Expr\" == \"expr\") || 1 == 1 ", Eq: true},
 		{In: "(\"expr\" == \"expr\" && \"Expr\" == \"expr\") || true == true ", Eq: true},
 		{In: "(\"expr\" == \"expr\" && \"Expr\" == \"expr\") || true == false ", Eq: false},
+		{In: `"say \"hi\"" == "say \u0022hi\u0022"`, Eq: true},
+		{In: `"é" == "\u00e9"`, Eq: true},
+		{In: `"a\tb" == "a b"`, Eq: false},
+		{In: "'a' == 97", Eq: true},
+		{In: "'a' < 'b'", Eq: true},
+		{In: `'a' == "a"`, Err: expr.ErrComparisonOperation},
 		{In: "true", Eq: true},
 		{In: "!false", Eq: true},
 		{In: "!false || false", Eq: true},
@@ -129,6 +156,11 @@ func TestBool(t *testing.T) {
 		{In: `"a" <= "a"`, Eq: true},
 		{In: `"a" >= "a"`, Eq: true},
 		{In: `0x4 << 0xA > 1024`, Eq: true},
+		{In: `false && "a" + 1 == 1`, Eq: false},
+		{In: `true || "a" + 1 == 1`, Eq: true},
+		{In: `true && "a" + 1 == 1`, Err: expr.ErrArithmeticOperation},
+		{In: `false || "a" + 1 == 1`, Err: expr.ErrArithmeticOperation},
+		{In: `1 && true`, Err: expr.ErrLogicalOperation},
 	}
 
 	for _, tc := range tt {
@@ -314,6 +346,7 @@ func TestInt(t *testing.T) {
 		{In: "10 + ((-5 * -10) * 10)", Eq: 510},
 		{In: "10 + ((-5 * -10) / -10) - 2", Eq: 3},
 		{In: "10 / 0", Eq: 0},
+		{In: "10 % 0", Eq: 0},
 		{In: "0b1100 | 0b0100", Eq: 12}, // = 1111
 		{In: "0b1100 ^ 0b0100", Eq: 8},  // = 1011
 		{In: "0b1100 & 0b0100", Eq: 4},  // = 0100
@@ -349,6 +382,7 @@ func TestInt64Strict(t *testing.T) {
 		{In: "4.23", Eq: 4},
 		{In: "4/0", Err: expr.ErrIntegerDividedByZero},
 		{In: "11 + 7", Eq: 18},
+		{In: "4/0 == 0 || true", Err: expr.ErrIntegerDividedByZero},
 	}
 
 	for _, tc := range tt {
@@ -364,3 +398,37 @@ func TestInt64Strict(t *testing.T) {
 		})
 	}
 }
+
+func TestUint64(t *testing.T) {
+	tt := []struct {
+		In  string
+		Eq  uint64
+		Err error
+	}{
+		{In: "0xFFFFFFFFFFFFFFFF", Eq: math.MaxUint64},
+		{In: "0xFFFFFFFFFFFFFFFF & 0xFF00", Eq: 0xFF00},
+		{In: "0xFFFFFFFFFFFFFFFF >> 60", Eq: 0xF},
+		{In: "1 << 63 >> 62", Eq: 2},
+		{In: "0 - 1", Eq: math.MaxUint64},
+		{In: "-1", Eq: math.MaxUint64},
+		{In: "18446744073709551615 / 2", Eq: 9223372036854775807},
+		{In: "7 % 3", Eq: 1},
+		{In: "7.9 + 1", Eq: 8},
+		{In: "4 / 0", Eq: 0},
+		{In: "18446744073709551616", Err: strconv.ErrRange},
+		{In: "1 < 2", Err: expr.ErrValueTypeMismatch},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.In, func(t *testing.T) {
+			v, err := expr.Uint64(tc.In)
+			if !errors.Is(err, tc.Err) {
+				t.Fatalf("expected error: %v, got: %v", tc.Err, err)
+			}
+			if v != tc.Eq {
+				t.Fatalf("expected %d, got: %d", tc.Eq, v)
+			}
+		})
+	}
+}
@@ -0,0 +1,352 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/muktihari/expr/internal/conv"
+)
+
+// foldedExpr is a constant expression that has been evaluated on Compile, e.g. "(1 - 0.15)" -> 0.85.
+// It embeds the original expression so its position is preserved.
+type foldedExpr struct {
+	ast.Expr
+	value value
+	pos   int // the evaluated position: the position of the expression inside the parentheses, if any.
+}
+
+var _ conv.Folded = (*foldedExpr)(nil)
+
+// Unfolded returns the original expression.
+func (f *foldedExpr) Unfolded() ast.Expr { return f.Expr }
+
+// Folded returns the folded form of the expression.
+func (f *foldedExpr) Folded() string {
+	if basicLit, ok := f.Expr.(*ast.BasicLit); ok {
+		return basicLit.Value
+	}
+	return formatLiteral(f.value)
+}
+
+// identityExpr is a binary expression whose one of the operands is an identity element of its operator, e.g. "x * 1"
+// and "true && x". Its result is x if x's value is safe, otherwise the binary operation is done as usual so the result
+// (including the error) is always the same as the original expression.
+type identityExpr struct {
+	*ast.BinaryExpr
+	x        ast.Expr             // the other operand.
+	constant ast.Expr             // the identity element: foldedExpr or identifier true or false.
+	swapped  bool                 // x is binaryExpr.Y.
+	safe     func(val value) bool // reports whether "x op constant" is x.
+}
+
+var _ conv.Folded = (*identityExpr)(nil)
+
+// Unfolded returns the original expression.
+func (f *identityExpr) Unfolded() ast.Expr { return f.BinaryExpr }
+
+// Folded returns the folded form of the expression.
+func (f *identityExpr) Folded() string { return conv.FormatFoldedExpr(f.x) }
+
+// visitIdentity evaluates identityExpr.
+func (v *Visitor) visitIdentity(identity *identityExpr) {
+	vc := pool.Get().(*Visitor)
+	defer pool.Put(vc)
+	vc.reset(v.options)
+
+	// true and false might be resolved into something else.
+	vc.Visit(identity.constant)
+	if ident, ok := identity.constant.(*ast.Ident); ok && (vc.err != nil ||
+		vc.value.Kind() != KindBoolean || vc.value.Bool() != (ident.Name == "true")) {
+		v.visitBinary(identity.BinaryExpr)
+		return
+	}
+
+	vx := pool.Get().(*Visitor)
+	defer pool.Put(vx)
+	vx.reset(v.options)
+
+	vx.Visit(identity.x)
+	if vx.err != nil {
+		v.err = vx.err
+		return
+	}
+	if identity.safe(vx.value) {
+		v.value = vx.value
+		return
+	}
+
+	if identity.swapped {
+		binary(v, vc, vx, identity.BinaryExpr)
+		return
+	}
+	binary(v, vx, vc, identity.BinaryExpr)
+}
+
+// fold folds constant sub-expressions of e using the given options and simplifies the identities, it returns
+// the folded expression and whether it is a constant. Identifiers (including true and false) are never constant
+// since they are resolved on evaluation, and so are Func calls since Func might not be pure. Sub-expression that
+// returns an error is not folded, so the error will be returned on evaluation.
+func fold(e ast.Expr, o *options) (ast.Expr, bool) {
+	switch d := e.(type) {
+	case *foldedExpr:
+		return d, true
+	case *ast.BasicLit:
+		return foldConstant(d, o)
+	case *ast.ParenExpr:
+		var ok bool
+		if d.X, ok = fold(d.X, o); ok {
+			return foldConstant(d, o)
+		}
+	case *ast.UnaryExpr:
+		var ok bool
+		if d.X, ok = fold(d.X, o); ok {
+			return foldConstant(d, o)
+		}
+	case *ast.BinaryExpr:
+		return foldBinary(d, o)
+	case *ast.CallExpr:
+		constant := true
+		for i := range d.Args {
+			var ok bool
+			d.Args[i], ok = fold(d.Args[i], o)
+			constant = constant && ok
+		}
+		if _, ok := lookupBuiltin(d, o); !ok {
+			return d, false
+		}
+		if constant {
+			return foldConstant(d, o)
+		}
+		if d.Fun.(*ast.Ident).Name == "iif" { // "iif(true, x, y)" -> x
+			if cond, ok := d.Args[0].(*foldedExpr); ok && cond.value.Kind() == KindBoolean {
+				branch := d.Args[2]
+				if cond.value.Bool() {
+					branch = d.Args[1]
+				}
+				_, ok := branch.(*foldedExpr)
+				return branch, ok
+			}
+		}
+	case *ast.IndexExpr:
+		var x, i bool
+		d.X, x = fold(d.X, o)
+		d.Index, i = fold(d.Index, o)
+		if x && i {
+			return foldConstant(d, o)
+		}
+	case *ast.SliceExpr:
+		constant := true
+		for _, e := range []*ast.Expr{&d.X, &d.Low, &d.High, &d.Max} {
+			if *e == nil {
+				continue
+			}
+			var ok bool
+			*e, ok = fold(*e, o)
+			constant = constant && ok
+		}
+		if constant {
+			return foldConstant(d, o)
+		}
+	case *ast.SelectorExpr:
+		d.X, _ = fold(d.X, o)
+	case *ast.CompositeLit:
+		constant := true
+		for i := range d.Elts {
+			var ok bool
+			d.Elts[i], ok = fold(d.Elts[i], o)
+			constant = constant && ok
+		}
+		if constant {
+			return foldConstant(d, o)
+		}
+	}
+	return e, false
+}
+
+// foldConstant evaluates constant e into foldedExpr, e is returned as is if the evaluation returns an error.
+func foldConstant(e ast.Expr, o *options) (ast.Expr, bool) {
+	v := pool.Get().(*Visitor)
+	defer pool.Put(v)
+	v.reset(*o)
+
+	v.Visit(e)
+	if v.err != nil {
+		return e, false
+	}
+	return &foldedExpr{Expr: e, value: v.value, pos: v.pos}, true
+}
+
+func foldBinary(binaryExpr *ast.BinaryExpr, o *options) (ast.Expr, bool) {
+	var x, y bool
+	binaryExpr.X, x = fold(binaryExpr.X, o)
+	binaryExpr.Y, y = fold(binaryExpr.Y, o)
+
+	if x && y {
+		return foldConstant(binaryExpr, o)
+	}
+
+	switch binaryExpr.Op {
+	case token.LAND, token.LOR:
+		return foldLogical(binaryExpr, x, y)
+	case token.ADD, token.SUB, token.MUL, token.QUO:
+		if x != y {
+			return foldArithmetic(binaryExpr, x, o), false
+		}
+	}
+	return binaryExpr, false
+}
+
+// foldLogical folds logical binaryExpr whose at most one of the operands is a constant: "false && Y" is false,
+// "true || Y" is true, while "true && x", "x && true", "false || x" and "x || false" are x.
+func foldLogical(binaryExpr *ast.BinaryExpr, x, y bool) (ast.Expr, bool) {
+	element := binaryExpr.Op == token.LAND // the identity element: true for "&&" and false for "||".
+
+	switch {
+	case x:
+		c := binaryExpr.X.(*foldedExpr).value
+		if c.Kind() != KindBoolean {
+			return binaryExpr, false
+		}
+		if c.Bool() != element {
+			return &foldedExpr{Expr: binaryExpr, value: c, pos: int(binaryExpr.Pos())}, true
+		}
+	case y:
+		c := binaryExpr.Y.(*foldedExpr).value
+		if c.Kind() != KindBoolean || c.Bool() != element {
+			return binaryExpr, false
+		}
+	case isBoolIdent(binaryExpr.X, element):
+		x = true
+	case !isBoolIdent(binaryExpr.Y, element):
+		return binaryExpr, false
+	}
+
+	identity := newIdentity(binaryExpr, x)
+	identity.safe = func(val value) bool { return val.Kind() == KindBoolean }
+	return identity, false
+}
+
+// newIdentity creates identityExpr from binaryExpr, x reports whether the identity element is binaryExpr.X.
+func newIdentity(binaryExpr *ast.BinaryExpr, x bool) *identityExpr {
+	if x {
+		return &identityExpr{BinaryExpr: binaryExpr, x: binaryExpr.Y, constant: binaryExpr.X, swapped: true}
+	}
+	return &identityExpr{BinaryExpr: binaryExpr, x: binaryExpr.X, constant: binaryExpr.Y}
+}
+
+// isBoolIdent reports whether e is identifier true or false that matches b.
+func isBoolIdent(e ast.Expr, b bool) bool {
+	ident, ok := e.(*ast.Ident)
+	return ok && ident.Name == strconv.FormatBool(b)
+}
+
+// foldArithmetic folds arithmetic binaryExpr whose one of the operands is a constant, x reports whether it is X:
+// "x * 1", "1 * x", "x + 0", "0 + x", "x - 0" and "x / 1" are x.
+func foldArithmetic(binaryExpr *ast.BinaryExpr, x bool, o *options) ast.Expr {
+	identity := newIdentity(binaryExpr, x)
+
+	c := identity.constant.(*foldedExpr).value
+	if c.Kind() != KindInt && c.Kind() != KindFloat {
+		return binaryExpr
+	}
+
+	var element float64
+	switch binaryExpr.Op {
+	case token.SUB:
+		if x {
+			return binaryExpr
+		}
+	case token.MUL:
+		element = 1
+	case token.QUO:
+		if x {
+			return binaryExpr
+		}
+		element = 1
+	}
+	if parseFloat(c) != element {
+		return binaryExpr
+	}
+
+	if identity.safe = numericIdentity(o.numericType, c.Kind(), binaryExpr.Op); identity.safe == nil {
+		return binaryExpr
+	}
+	return identity
+}
+
+// numericIdentity returns a function that reports whether "x op c" is exactly x (including its Kind) for the given
+// numeric type, where c is the identity element of op whose Kind is constantKind. It returns nil if there is none.
+func numericIdentity(numericType NumericType, constantKind Kind, op token.Token) func(val value) bool {
+	var kinds []Kind
+	switch numericType {
+	case NumericTypeAuto, NumericTypeFloat:
+		kinds = []Kind{KindFloat}
+	case NumericTypeGo:
+		kinds = []Kind{KindFloat}
+		if constantKind == KindInt {
+			kinds = append(kinds, KindInt)
+		}
+	case NumericTypeInt:
+		kinds = []Kind{KindInt}
+	default:
+		return nil
+	}
+	return func(val value) bool {
+		for _, kind := range kinds {
+			if val.Kind() != kind {
+				continue
+			}
+			if kind == KindFloat && op == token.ADD { // -0 + 0 is +0
+				f := val.Float64()
+				return f != 0 || !math.Signbit(f)
+			}
+			return true
+		}
+		return false
+	}
+}
+
+// formatLiteral formats val as expr's literal.
+func formatLiteral(val value) string {
+	switch val.Kind() {
+	case KindFloat:
+		s := strconv.FormatFloat(val.Float64(), 'g', -1, 64)
+		if !strings.ContainsAny(s, ".eEnN") { // keep it as a float literal, but not for NaN and Inf.
+			s += ".0"
+		}
+		return s
+	case KindImag:
+		return strconv.FormatComplex(val.Complex128(), 'g', -1, 128)
+	case KindRune:
+		return strconv.QuoteRune(rune(val.Int64()))
+	case KindString:
+		return strconv.Quote(val.String())
+	case KindNil:
+		return "nil"
+	case KindList:
+		list := val.List()
+		elts := make([]string, len(list))
+		for i := range list {
+			elts[i] = formatLiteral(list[i])
+		}
+		return "[]any{" + strings.Join(elts, ", ") + "}"
+	}
+	return fmt.Sprint(val.Any())
+}
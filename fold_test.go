@@ -0,0 +1,150 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"fmt"
+	"go/parser"
+	"math"
+	"testing"
+)
+
+func TestFold(t *testing.T) {
+	tt := []struct {
+		in       string
+		options  []Option
+		expected string
+	}{
+		{in: "price * (1 - 0.15)", expected: "price * 0.85"},
+		{in: "1 + 2 * 3", expected: "7.0"},
+		{in: "1 + 2 * 3", options: []Option{WithNumericType(NumericTypeInt)}, expected: "7"},
+		{in: "price * 1", expected: "price"},
+		{in: "1 * price + 0", expected: "price"},
+		{in: "price * (2 - 1)", expected: "price"},
+		{in: "price - 0", expected: "price"},
+		{in: "0 - price", expected: "0 - price"},
+		{in: "price / 1", expected: "price"},
+		{in: "1 / price", expected: "1 / price"},
+		{in: "price * 2", expected: "price * 2"},
+		{in: "price * 1", options: []Option{WithNumericType(NumericTypeDecimal)}, expected: "price * 1"},
+		{in: "true && ok", expected: "ok"},
+		{in: "ok || false", expected: "ok"},
+		{in: "1 > 2 && ok", expected: "false"},
+		{in: "false && ok", expected: "false && ok"}, // false might be resolved into something else
+		{in: "1 > 2 || ok", expected: "ok"},
+		{in: "ok && false", expected: "ok && false"},
+		{in: `"a" + "b" + name`, expected: `"ab" + name`},
+		{in: `"abc"[1:] == name`, expected: `"bc" == name`},
+		{in: "[]any{1, 2}[0] + price", expected: "1 + price"},
+		{in: "in(price, []any{1, 1 + 1})", expected: "in(price, []any{1, 2.0})"},
+		{in: "iif(1 > 2, price, 0.5 * 2)", expected: "1.0"},
+		{in: "iif(1 < 2, price, 0)", expected: "price"},
+		{in: "iif(ok, price, 1 + 1)", expected: "iif(ok, price, 2.0)"},
+		{in: "int(price) + int(1.5 + 0.5)", expected: "int(price) + 2"},
+		{in: "len(\"ab\") + price", expected: "len(\"ab\") + price"},     // Func is not folded
+		{in: `true && price + (1 - "a")`, expected: `price + (1 - "a")`}, // error is left for evaluation
+		{in: "(1 + 2) * price.x", expected: "3.0 * price.x"},
+	}
+
+	for i, tc := range tt {
+		tc := tc
+		t.Run(fmt.Sprintf("[%d] %s", i, tc.in), func(t *testing.T) {
+			opts := append([]Option{WithFunctions(map[string]Func{
+				"len": {Args: []Kind{KindString}, Return: KindInt, Fn: func(args ...interface{}) (interface{}, error) {
+					return len(args[0].(string)), nil
+				}},
+			})}, tc.options...)
+
+			p, err := Compile(tc.in, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := p.String(); s != tc.expected {
+				t.Fatalf("expected: %q, got: %q", tc.expected, s)
+			}
+		})
+	}
+}
+
+func TestFoldEquivalence(t *testing.T) {
+	env := map[string]interface{}{
+		"x":  2.5,
+		"i":  int64(3),
+		"u":  uint64(3),
+		"r":  'a',
+		"s":  "a",
+		"ok": true,
+		"n":  nil,
+		"nz": math.Copysign(0, -1),
+	}
+	shadowed := map[string]interface{}{"true": false, "false": 1, "ok": false}
+
+	ins := []string{
+		"x * 1", "1 * x", "x + 0", "0 + x", "x - 0", "x / 1", "x * 1.0",
+		"i * 1", "i + 0", "i / 1", "i * 1.0", "u * 1", "r + 0", "s * 1", "s + 0", "ok * 1", "n * 1", "n + 0",
+		"nz + 0", "0 + nz", "nz - 0", "nz * 1", "-0.0 + 0", "-nz + 0",
+		"true && ok", "ok && true", "false || ok", "ok || false", "true && x", "x || false", "false && x", "true || x",
+		"true && n", "1 && ok", "ok && 1", "false && ok", "ok || true",
+		"iif(true, x, s)", "iif(false, x, s) + 1", "iif(1, x, s)",
+		`"a" + s`, `s + 1 + 2`, "x + 1 / 0", "(1 + 2) * x", "(1 + 2) + s", "((1)) + s", "s + (1 + 2)", "x * (3 - 2)", "[]any{1, x}[0] + 1", "in(x, []any{1, 2.5})",
+		"1 << 70 + x", "9223372036854775807 + 1 + x",
+	}
+
+	numericTypes := []NumericType{
+		NumericTypeAuto, NumericTypeGo, NumericTypeComplex, NumericTypeFloat, NumericTypeInt, NumericTypeUint,
+		NumericTypeBig, NumericTypeDecimal,
+	}
+
+	for _, in := range ins {
+		for _, numericType := range numericTypes {
+			for _, env := range []map[string]interface{}{env, shadowed} {
+				in, numericType, env := in, numericType, env
+				t.Run(fmt.Sprintf("%d: %s %v", numericType, in, env), func(t *testing.T) {
+					o := defaultOptions()
+					WithEnv(env)(&o)
+					WithNumericType(numericType)(&o)
+
+					e, err := parser.ParseExpr(in)
+					if err != nil {
+						t.Fatal(err)
+					}
+					expectedVal, expectedErr := visit(e, o)
+
+					e, err = parser.ParseExpr(in)
+					if err != nil {
+						t.Fatal(err)
+					}
+					folded, _ := fold(e, &o)
+					val, err := visit(folded, o)
+
+					if fmt.Sprint(err) != fmt.Sprint(expectedErr) {
+						t.Fatalf("expected err: %v, got: %v", expectedErr, err)
+					}
+					if err != nil {
+						return
+					}
+					if val.Kind() != expectedVal.Kind() {
+						t.Fatalf("expected kind: %s, got: %s", expectedVal.Kind(), val.Kind())
+					}
+					if s, expected := fmt.Sprint(val.Any()), fmt.Sprint(expectedVal.Any()); s != expected {
+						t.Fatalf("expected: %s, got: %s", expected, s)
+					}
+					if val.Kind() == KindFloat && math.Signbit(val.Float64()) != math.Signbit(expectedVal.Float64()) {
+						t.Fatalf("expected: %v, got: %v", expectedVal.Float64(), val.Float64())
+					}
+				})
+			}
+		}
+	}
+}
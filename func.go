@@ -0,0 +1,286 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"fmt"
+	"go/ast"
+	"math"
+
+	"github.com/muktihari/expr/internal/conv"
+)
+
+// Func is a Go function that can be called in expr string, e.g. "tier(qty)".
+//
+// Arguments are passed to Fn as Go's primitive types based on the declared Kind:
+//   - KindBoolean: bool
+//   - KindInt: int64 (float or complex argument is accepted only if it has no fractional or imaginary part,
+//     unless NumericTypeInt is used where it will be truncated)
+//   - KindUint: uint64 (the same as KindInt but negative argument is not accepted, unless NumericTypeUint is used)
+//   - KindFloat: float64 (complex argument is accepted only if it has no imaginary part)
+//   - KindImag: complex128
+//   - KindString: string
+//   - KindAny: any of the above, numeric argument will be converted based on NumericType (except NumericTypeAuto,
+//     NumericTypeGo, NumericTypeBig and NumericTypeDecimal), so it might be *big.Int, *big.Float or Decimal as well.
+type Func struct {
+	Args     []Kind                                         // Kind of each argument.
+	Optional int                                            // The number of the last Args that can be omitted.
+	Variadic bool                                           // The last Args's Kind can be repeated zero or more times.
+	Return   Kind                                           // Kind of the returned value, KindAny if it varies.
+	Fn       func(args ...interface{}) (interface{}, error) // Fn's error will be wrapped in SyntaxError.
+}
+
+// lookupFunc finds Func called by callExpr and validates the number of its arguments.
+func lookupFunc(callExpr *ast.CallExpr, o *options) (Func, error) {
+	ident, ok := callExpr.Fun.(*ast.Ident)
+	if !ok {
+		return Func{}, &SyntaxError{
+			Msg: "could not call \"" + conv.FormatExpr(callExpr.Fun) + "\": not a function name",
+			Pos: int(callExpr.Fun.Pos()),
+			Err: ErrFunctionCall,
+		}
+	}
+
+	fn, ok := o.functions[ident.Name]
+	if !ok {
+		return Func{}, &SyntaxError{
+			Msg: "function \"" + ident.Name + "\" is undefined",
+			Pos: int(ident.NamePos),
+			Err: ErrFunctionCall,
+		}
+	}
+
+	minArgs, maxArgs := len(fn.Args), len(fn.Args)
+	if fn.Variadic && len(fn.Args) > 0 {
+		minArgs, maxArgs = len(fn.Args)-1, -1
+	}
+	if fn.Optional > 0 {
+		minArgs = len(fn.Args) - fn.Optional
+		if minArgs < 0 {
+			minArgs = 0
+		}
+	}
+	if err := validateArgs(callExpr, ident.Name, minArgs, maxArgs); err != nil {
+		return Func{}, err
+	}
+
+	return fn, nil
+}
+
+// validateArgs validates the number of callExpr's arguments, maxArgs -1 means there is no maximum.
+func validateArgs(callExpr *ast.CallExpr, name string, minArgs, maxArgs int) error {
+	if callExpr.Ellipsis.IsValid() {
+		return &SyntaxError{
+			Msg: "could not call \"" + name + "\" with \"...\"",
+			Pos: int(callExpr.Ellipsis),
+			Err: ErrFunctionCall,
+		}
+	}
+
+	switch {
+	case len(callExpr.Args) < minArgs:
+		return &SyntaxError{
+			Msg: fmt.Sprintf("not enough arguments in call to %q: have %d, want %d", name, len(callExpr.Args), minArgs),
+			Pos: int(callExpr.Rparen),
+			Err: ErrFunctionCall,
+		}
+	case maxArgs != -1 && len(callExpr.Args) > maxArgs:
+		return &SyntaxError{
+			Msg: fmt.Sprintf("too many arguments in call to %q: have %d, want %d", name, len(callExpr.Args), maxArgs),
+			Pos: int(callExpr.Args[maxArgs].Pos()),
+			Err: ErrFunctionCall,
+		}
+	}
+	return nil
+}
+
+// builtin is a function that is evaluated by Visitor itself since it needs to access the unevaluated arguments,
+// e.g. to evaluate them lazily or to compare them without converting into Go's primitive types.
+type builtin struct {
+	minArgs, maxArgs int // maxArgs -1 means there is no maximum.
+	fn               func(v *Visitor, callExpr *ast.CallExpr)
+	check            func(c *checker, callExpr *ast.CallExpr, args []checked) checked // infers the result for Check
+}
+
+var builtins map[string]builtin
+
+func init() { // declared in init to avoid initialization cycle since builtin calls Visitor's Visit.
+	builtins = map[string]builtin{
+		"in":       {minArgs: 2, maxArgs: 2, fn: builtinIn, check: checkIn},
+		"coalesce": {minArgs: 1, maxArgs: -1, fn: builtinCoalesce, check: checkCoalesce},
+		"iif":      {minArgs: 3, maxArgs: 3, fn: builtinIif, check: checkIif},
+		"int":      {minArgs: 1, maxArgs: 1, fn: builtinInt, check: checkConversion(KindInt, isNumeric, KindString)},
+		"float64":  {minArgs: 1, maxArgs: 1, fn: builtinFloat64, check: checkConversion(KindFloat, isNumeric, KindString)},
+		"string":   {minArgs: 1, maxArgs: 1, fn: builtinString, check: checkConversion(KindString, isNumeric, KindString, KindBoolean)},
+		"bool":     {minArgs: 1, maxArgs: 1, fn: builtinBool, check: checkConversion(KindBoolean, nil, KindString, KindBoolean)},
+	}
+}
+
+// lookupBuiltin finds builtin called by callExpr, function registered using WithFunctions takes precedence.
+func lookupBuiltin(callExpr *ast.CallExpr, o *options) (builtin, bool) {
+	ident, ok := callExpr.Fun.(*ast.Ident)
+	if !ok {
+		return builtin{}, false
+	}
+	if _, ok := o.functions[ident.Name]; ok {
+		return builtin{}, false
+	}
+	b, ok := builtins[ident.Name]
+	return b, ok
+}
+
+// argKind returns declared Kind of the i-th argument of fn.
+func argKind(fn Func, i int) Kind {
+	if i >= len(fn.Args) {
+		return fn.Args[len(fn.Args)-1] // variadic
+	}
+	return fn.Args[i]
+}
+
+// call calls fn with its evaluated arguments args and sets v's value with the returned value.
+func call(v *Visitor, fn Func, args []*Visitor, callExpr *ast.CallExpr) {
+	name := conv.FormatExpr(callExpr.Fun)
+
+	in := make([]interface{}, len(args))
+	for i, arg := range args {
+		kind := argKind(fn, i)
+		x, ok := convertArg(arg.value, kind, v.options.numericType)
+		if !ok {
+			v.err = newArgumentError(callExpr, i, arg.value, kind.String())
+			return
+		}
+		in[i] = x
+	}
+
+	if fn.Fn == nil {
+		v.err = &SyntaxError{
+			Msg: "function \"" + name + "\" has nil Fn",
+			Pos: int(callExpr.Pos()),
+			Err: ErrFunctionCall,
+		}
+		return
+	}
+
+	out, err := fn.Fn(in...)
+	if err != nil {
+		v.err = &SyntaxError{
+			Msg: "call to \"" + name + "\" failed",
+			Pos: int(callExpr.Pos()),
+			Err: err,
+		}
+		return
+	}
+
+	val, ok := valueOf(out)
+	if !ok || (fn.Return != KindAny && val.Kind() != fn.Return) {
+		v.err = &SyntaxError{
+			Msg: fmt.Sprintf("function %q returns \"%v\" (%T) which is not %s", name, out, out, fn.Return),
+			Pos: int(callExpr.Pos()),
+			Err: ErrFunctionCall,
+		}
+		return
+	}
+	v.value = val
+}
+
+// newArgumentError creates error for the i-th argument of callExpr whose value val is not the expected one.
+func newArgumentError(callExpr *ast.CallExpr, i int, val value, expected string) error {
+	return &SyntaxError{
+		Msg: fmt.Sprintf("argument %d in call to %q: result of %q is \"%v\" which is not %s",
+			i+1, conv.FormatExpr(callExpr.Fun), conv.FormatExpr(callExpr.Args[i]), val.Any(), expected),
+		Pos: int(callExpr.Args[i].Pos()),
+		Err: ErrFunctionCall,
+	}
+}
+
+// convertArg converts val into Go's primitive type based on declared kind.
+func convertArg(val value, kind Kind, numericType NumericType) (interface{}, bool) {
+	val = runeAsInt(val)
+	isNumeric := val.Kind() > numeric_beg && val.Kind() < numeric_end
+
+	switch kind {
+	case KindAny:
+		if !isNumeric {
+			return val.Any(), val.Kind() != KindIllegal
+		}
+		switch numericType {
+		case NumericTypeComplex:
+			return parseComplex(val), true
+		case NumericTypeFloat:
+			return parseFloat(val), true
+		case NumericTypeInt:
+			return parseInt(val), true
+		case NumericTypeUint:
+			return parseUint(val), true
+		}
+		return val.Any(), true
+	case KindInt:
+		switch {
+		case val.Kind() == KindInt:
+			return val.Int64(), true
+		case val.Kind() == KindUint:
+			return int64(val.Uint64()), val.Uint64() <= math.MaxInt64
+		case val.Kind() == KindBigInt:
+			return val.BigInt().Int64(), val.BigInt().IsInt64()
+		case val.Kind() == KindDecimal:
+			d := val.Decimal()
+			if !d.IsInt() {
+				return nil, false
+			}
+			i := d.round(0, RoundDown).int()
+			return i.Int64(), i.IsInt64()
+		case !isNumeric:
+			return nil, false
+		case numericType == NumericTypeInt:
+			return parseInt(val), true
+		}
+		c := parseComplex(val)
+		if imag(c) != 0 {
+			return nil, false
+		}
+		return convertToInt64(float64Value(real(c)))
+	case KindUint:
+		switch {
+		case val.Kind() == KindUint:
+			return val.Uint64(), true
+		case val.Kind() == KindBigInt:
+			return val.BigInt().Uint64(), val.BigInt().IsUint64()
+		case !isNumeric:
+			return nil, false
+		case numericType == NumericTypeUint:
+			return parseUint(val), true
+		}
+		i, ok := convertArg(val, KindInt, numericType)
+		if !ok || i.(int64) < 0 {
+			return nil, false
+		}
+		return uint64(i.(int64)), true
+	case KindFloat:
+		if !isNumeric || imag(parseComplex(val)) != 0 {
+			return nil, false
+		}
+		return parseFloat(val), true
+	case KindImag:
+		if !isNumeric {
+			return nil, false
+		}
+		return parseComplex(val), true
+	case KindBoolean, KindString:
+		if val.Kind() != kind {
+			return nil, false
+		}
+		return val.Any(), true
+	}
+	return nil, false
+}
@@ -0,0 +1,174 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"strings"
+	"testing"
+)
+
+var errTierOutOfRange = errors.New("tier out of range")
+
+var testFunctions = map[string]Func{
+	"tier": {
+		Args:   []Kind{KindInt},
+		Return: KindFloat,
+		Fn: func(args ...interface{}) (interface{}, error) {
+			switch qty := args[0].(int64); {
+			case qty < 0:
+				return nil, errTierOutOfRange
+			case qty >= 100:
+				return 0.1, nil
+			}
+			return 0.0, nil
+		},
+	},
+	"max": {
+		Args:     []Kind{KindFloat, KindFloat},
+		Variadic: true,
+		Return:   KindFloat,
+		Fn: func(args ...interface{}) (interface{}, error) {
+			m := args[0].(float64)
+			for _, arg := range args[1:] {
+				if f := arg.(float64); f > m {
+					m = f
+				}
+			}
+			return m, nil
+		},
+	},
+	"upper": {
+		Args:   []Kind{KindString},
+		Return: KindString,
+		Fn: func(args ...interface{}) (interface{}, error) {
+			return strings.ToUpper(args[0].(string)), nil
+		},
+	},
+	"typeof": {
+		Args:   []Kind{KindAny},
+		Return: KindString,
+		Fn: func(args ...interface{}) (interface{}, error) {
+			return fmt.Sprintf("%T", args[0]), nil
+		},
+	},
+	"conj": {
+		Args:   []Kind{KindImag},
+		Return: KindImag,
+		Fn: func(args ...interface{}) (interface{}, error) {
+			c := args[0].(complex128)
+			return complex(real(c), -imag(c)), nil
+		},
+	},
+	"not": {
+		Args:   []Kind{KindBoolean},
+		Return: KindBoolean,
+		Fn: func(args ...interface{}) (interface{}, error) {
+			return !args[0].(bool), nil
+		},
+	},
+	"bad": {
+		Return: KindInt,
+		Fn: func(args ...interface{}) (interface{}, error) {
+			return "not an int", nil
+		},
+	},
+	"nilfn": {},
+}
+
+func TestVisitCall(t *testing.T) {
+	tt := []struct {
+		in            string
+		numericType   NumericType
+		expectedValue value
+		expectedErr   error
+		expectedPos   int
+	}{
+		{in: "tier(150)", expectedValue: float64Value(0.1)},
+		{in: "tier(50) + 1", expectedValue: float64Value(1)},
+		{in: "tier(100.0)", expectedValue: float64Value(0.1)},
+		{in: "tier(100.5)", expectedErr: ErrFunctionCall, expectedPos: 6},
+		{in: "tier(100.5)", numericType: NumericTypeInt, expectedValue: float64Value(0.1)},
+		{in: "tier(\"a\")", expectedErr: ErrFunctionCall, expectedPos: 6},
+		{in: "tier(-1)", expectedErr: errTierOutOfRange, expectedPos: 1},
+		{in: "tier()", expectedErr: ErrFunctionCall, expectedPos: 6},
+		{in: "tier(1, 2)", expectedErr: ErrFunctionCall, expectedPos: 9},
+		{in: "max(1, 2)", expectedValue: float64Value(2)},
+		{in: "max(1, 2, 3.5, -1)", expectedValue: float64Value(3.5)},
+		{in: "max(1)", expectedValue: float64Value(1)},
+		{in: "max()", expectedErr: ErrFunctionCall, expectedPos: 5},
+		{in: "max(1, 2+1i)", expectedErr: ErrFunctionCall, expectedPos: 8},
+		{in: "max(1, (2+1i) - 1i)", expectedValue: float64Value(2)},
+		{in: "max(1, true)", expectedErr: ErrFunctionCall, expectedPos: 8},
+		{in: "max(1, 1 + true)", expectedErr: ErrArithmeticOperation},
+		{in: "upper(\"abc\") == \"ABC\"", expectedValue: boolValue(true)},
+		{in: "typeof(1)", expectedValue: stringValue("int64")},
+		{in: "typeof(1)", numericType: NumericTypeFloat, expectedValue: stringValue("float64")},
+		{in: "typeof(1)", numericType: NumericTypeComplex, expectedValue: stringValue("complex128")},
+		{in: "typeof(1.5)", numericType: NumericTypeInt, expectedValue: stringValue("int64")},
+		{in: "typeof(true)", expectedValue: stringValue("bool")},
+		{in: "conj(1+2i)", expectedValue: complex128Value(1 - 2i)},
+		{in: "conj(2)", expectedValue: complex128Value(2)},
+		{in: "conj(\"2\")", expectedErr: ErrFunctionCall, expectedPos: 6},
+		{in: "not(true)", expectedValue: boolValue(false)},
+		{in: "not(1)", expectedErr: ErrFunctionCall, expectedPos: 5},
+		{in: "bad()", expectedErr: ErrFunctionCall, expectedPos: 1},
+		{in: "nilfn()", expectedErr: ErrFunctionCall, expectedPos: 1},
+		{in: "undefined(1)", expectedErr: ErrFunctionCall, expectedPos: 1},
+		{in: "(tier)(1)", expectedErr: ErrFunctionCall, expectedPos: 1},
+		{in: "max(1, x...)", expectedErr: ErrFunctionCall, expectedPos: 9},
+	}
+
+	for i, tc := range tt {
+		tc := tc
+		t.Run(fmt.Sprintf("[%d] %s", i, tc.in), func(t *testing.T) {
+			e, err := parser.ParseExpr(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := NewVisitor(WithNumericType(tc.numericType), WithFunctions(testFunctions))
+			ast.Walk(v, e)
+
+			if err := v.Err(); !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected err: %v, got: %v", tc.expectedErr, err)
+			}
+			var syntaxErr *SyntaxError
+			if errors.As(v.Err(), &syntaxErr) && tc.expectedPos != 0 && syntaxErr.Pos != tc.expectedPos {
+				t.Fatalf("expected pos: %d, got: %d", tc.expectedPos, syntaxErr.Pos)
+			}
+			if val := v.ValueAny(); val != tc.expectedValue.Any() {
+				t.Fatalf("expected val: %v (%T), got: %v (%T)", tc.expectedValue.Any(), tc.expectedValue.Any(), val, val)
+			}
+		})
+	}
+}
+
+func TestWithFunctions(t *testing.T) {
+	f := func(args ...interface{}) (interface{}, error) { return nil, nil }
+
+	var o options
+	WithFunctions(map[string]Func{"a": {Fn: f}, "b": {Fn: f}})(&o)
+	WithFunctions(map[string]Func{"b": {Return: KindInt, Fn: f}, "c": {Fn: f}})(&o)
+
+	if len(o.functions) != 3 {
+		t.Fatalf("expected len: %d, got: %d", 3, len(o.functions))
+	}
+	if o.functions["b"].Return != KindInt {
+		t.Fatalf("expected function \"b\" is replaced")
+	}
+}
@@ -14,7 +14,9 @@
 package conv
 
 import (
+	"fmt"
 	"go/ast"
+	"strings"
 )
 
 // FormatExpr formats ast.Expr into expr's string format.
@@ -23,3 +25,47 @@ func FormatExpr(e ast.Expr) string {
 	ast.Walk(v, e)
 	return v.Value()
 }
+
+// FormatFoldedExpr formats ast.Expr the same as FormatExpr but Folded is formatted using its folded form,
+// e.g. "price * (1 - 0.15)" -> "price * 0.85".
+func FormatFoldedExpr(e ast.Expr) string {
+	v := &Visitor{folded: true}
+	ast.Walk(v, e)
+	return v.Value()
+}
+
+// DescribeSyntax describes node's syntax in human readable form including its formatted expression if any,
+// e.g. `selector expression "foo.bar"` for *ast.SelectorExpr.
+func DescribeSyntax(node ast.Node) string {
+	name := syntaxName(node)
+	if e, ok := node.(ast.Expr); ok {
+		if s := FormatExpr(e); s != "" {
+			return name + " \"" + s + "\""
+		}
+	}
+	return name
+}
+
+func syntaxName(node ast.Node) string {
+	switch node.(type) {
+	case *ast.SelectorExpr:
+		return "selector expression"
+	case *ast.CompositeLit:
+		return "composite literal"
+	case *ast.FuncLit:
+		return "function literal"
+	case *ast.TypeAssertExpr:
+		return "type assertion"
+	case *ast.StarExpr:
+		return "pointer indirection"
+	case *ast.KeyValueExpr:
+		return "key-value expression"
+	case *ast.Ellipsis:
+		return "ellipsis"
+	case *ast.ArrayType, *ast.StructType, *ast.FuncType, *ast.InterfaceType, *ast.MapType, *ast.ChanType:
+		return "type expression"
+	case *ast.BadExpr:
+		return "bad expression"
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", node), "*ast.")
+}
@@ -69,3 +69,77 @@ func TestFormatExpr(t *testing.T) {
 
 	}
 }
+
+type folded struct {
+	ast.Expr
+	value string
+}
+
+func (f *folded) Folded() string     { return f.value }
+func (f *folded) Unfolded() ast.Expr { return f.Expr }
+
+func TestFormatFoldedExpr(t *testing.T) {
+	// price * (1 - 0.15) + 1
+	paren := &ast.ParenExpr{
+		Lparen: 9,
+		X: &ast.BinaryExpr{
+			X:     &ast.BasicLit{Kind: token.INT, Value: "1", ValuePos: 10},
+			Op:    token.SUB,
+			OpPos: 12,
+			Y:     &ast.BasicLit{Kind: token.FLOAT, Value: "0.15", ValuePos: 14},
+		},
+		Rparen: 18,
+	}
+	e := &ast.BinaryExpr{
+		X: &ast.BinaryExpr{
+			X:     &ast.Ident{Name: "price", NamePos: 1},
+			Op:    token.MUL,
+			OpPos: 7,
+			Y:     &folded{Expr: paren, value: "0.85"},
+		},
+		Op:    token.ADD,
+		OpPos: 20,
+		Y:     &ast.BasicLit{Kind: token.INT, Value: "1", ValuePos: 22},
+	}
+
+	if s, expected := conv.FormatFoldedExpr(e), "price * 0.85 + 1"; s != expected {
+		t.Fatalf("expected value: %s, got: %s", expected, s)
+	}
+	if s, expected := conv.FormatExpr(e), "price * (1 - 0.15) + 1"; s != expected {
+		t.Fatalf("expected value: %s, got: %s", expected, s)
+	}
+}
+
+func TestDescribeSyntax(t *testing.T) {
+	tt := []struct {
+		in  ast.Node
+		val string
+	}{
+		{
+			in:  &ast.SelectorExpr{X: &ast.Ident{Name: "foo"}, Sel: &ast.Ident{Name: "bar"}},
+			val: "selector expression \"foo.bar\"",
+		},
+		{
+			in:  &ast.StarExpr{X: &ast.Ident{Name: "p"}},
+			val: "pointer indirection \"*p\"",
+		},
+		{in: &ast.CompositeLit{}, val: "composite literal"},
+		{in: &ast.FuncLit{Type: &ast.FuncType{}}, val: "function literal"},
+		{in: &ast.TypeAssertExpr{X: &ast.Ident{Name: "x"}}, val: "type assertion"},
+		{in: &ast.KeyValueExpr{Key: &ast.Ident{Name: "k"}}, val: "key-value expression"},
+		{in: &ast.Ellipsis{}, val: "ellipsis"},
+		{in: &ast.MapType{}, val: "type expression"},
+		{in: &ast.BadExpr{}, val: "bad expression"},
+		{in: &ast.ExprStmt{}, val: "ExprStmt"},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.val, func(t *testing.T) {
+			s := conv.DescribeSyntax(tc.in)
+			if s != tc.val {
+				t.Fatalf("expected value: %s, got: %s", tc.val, s)
+			}
+		})
+	}
+}
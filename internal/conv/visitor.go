@@ -18,10 +18,20 @@ import (
 	"strings"
 )
 
+// Folded is implemented by an expression that has been simplified by constant folding, e.g. "(1 - 0.15)" that is
+// folded into 0.85. Its position is the original expression's position.
+type Folded interface {
+	ast.Expr
+	Folded() string     // the folded form, e.g. "0.85".
+	Unfolded() ast.Expr // the original expression, e.g. "(1 - 0.15)".
+}
+
 // Visitor satisfies ast.Visitor and it will turn given expression type into a string representation.
 type Visitor struct {
-	value string
-	pos   int
+	value  string
+	pos    int
+	end    int  // only if it contains Folded whose length is different from the original expression, 0 otherwise.
+	folded bool // format Folded using its folded form instead of the original expression.
 }
 
 var _ ast.Visitor = &Visitor{} // satisfies ast.Visitor
@@ -37,26 +47,116 @@ func (v *Visitor) Visit(node ast.Node) ast.Visitor {
 	v.pos = int(node.Pos())
 
 	switch d := node.(type) {
+	case Folded:
+		if !v.folded {
+			return v.Visit(d.Unfolded())
+		}
+		v.value = d.Folded()
+		v.end = int(d.End())
+		return nil
 	case *ast.ParenExpr:
-		vx := &Visitor{}
+		vx := v.child()
 		ast.Walk(vx, d.X)
 		spacerX := createSpacer(vx.pos - int(d.Lparen) - 1)
-		spacerY := createSpacer(int(d.Rparen) - (int(vx.pos) + len(vx.value)))
+		spacerY := createSpacer(int(d.Rparen) - vx.endPos())
 		v.value = "(" + spacerX + vx.value + spacerY + ")"
+		if vx.end != 0 {
+			v.end = int(d.Rparen) + 1
+		}
 		return nil
 	case *ast.UnaryExpr:
-		vx := &Visitor{}
+		vx := v.child()
 		ast.Walk(vx, d.X)
 		spacer := createSpacer(vx.pos - int(d.OpPos) - 1)
 		v.value = d.Op.String() + spacer + vx.value
+		v.end = vx.end
 		return nil
 	case *ast.BinaryExpr:
-		vx, vy := &Visitor{}, &Visitor{}
+		vx, vy := v.child(), v.child()
 		ast.Walk(vx, d.X)
 		ast.Walk(vy, d.Y)
-		spacerX := createSpacer(int(d.OpPos) - (int(vx.pos) + len(vx.value)))
+		spacerX := createSpacer(int(d.OpPos) - vx.endPos())
 		spacerY := createSpacer(int(vy.pos) - (int(d.OpPos) + len(d.Op.String())))
 		v.value = vx.value + spacerX + d.Op.String() + spacerY + vy.value
+		v.end = vy.end
+		return nil
+	case *ast.CallExpr:
+		vf := v.child()
+		ast.Walk(vf, d.Fun)
+		args := make([]string, len(d.Args))
+		for i := range d.Args {
+			va := v.child()
+			ast.Walk(va, d.Args[i])
+			args[i] = va.value
+		}
+		v.value = vf.value + "(" + strings.Join(args, ", ") + ")"
+		v.end = int(d.Rparen) + 1 // arguments are always separated by ", " regardless of the original spacing.
+		return nil
+	case *ast.IndexExpr:
+		vx, vi := v.child(), v.child()
+		ast.Walk(vx, d.X)
+		ast.Walk(vi, d.Index)
+		v.value = vx.value + "[" + vi.value + "]"
+		if vx.end != 0 || vi.end != 0 {
+			v.end = int(d.Rbrack) + 1
+		}
+		return nil
+	case *ast.SliceExpr:
+		vx, vl, vh, vm := v.child(), v.child(), v.child(), v.child()
+		ast.Walk(vx, d.X)
+		ast.Walk(vl, d.Low)
+		ast.Walk(vh, d.High)
+		ast.Walk(vm, d.Max)
+		v.value = vx.value + "[" + vl.value + ":" + vh.value
+		if d.Slice3 {
+			v.value += ":" + vm.value
+		}
+		v.value += "]"
+		if vx.end != 0 || vl.end != 0 || vh.end != 0 || vm.end != 0 {
+			v.end = int(d.Rbrack) + 1
+		}
+		return nil
+	case *ast.SelectorExpr:
+		vx := v.child()
+		ast.Walk(vx, d.X)
+		v.value = vx.value + "." + d.Sel.Name
+		if vx.end != 0 {
+			v.end = int(d.Sel.End())
+		}
+		return nil
+	case *ast.StarExpr:
+		vx := v.child()
+		ast.Walk(vx, d.X)
+		v.value = "*" + vx.value
+		return nil
+	case *ast.CompositeLit:
+		vt := v.child()
+		ast.Walk(vt, d.Type)
+		if vt.value == "" { // unsupported type
+			return nil
+		}
+		elts := make([]string, len(d.Elts))
+		for i := range d.Elts {
+			ve := v.child()
+			ast.Walk(ve, d.Elts[i])
+			elts[i] = ve.value
+		}
+		v.value = vt.value + "{" + strings.Join(elts, ", ") + "}"
+		v.end = int(d.Rbrace) + 1
+		return nil
+	case *ast.ArrayType:
+		vl, ve := v.child(), v.child()
+		ast.Walk(vl, d.Len)
+		ast.Walk(ve, d.Elt)
+		if ve.value == "" {
+			return nil
+		}
+		v.value = "[" + vl.value + "]" + ve.value
+		return nil
+	case *ast.InterfaceType:
+		if d.Methods == nil || len(d.Methods.List) == 0 {
+			v.value = "interface{}"
+		}
 		return nil
 	case *ast.BasicLit:
 		v.value = d.Value
@@ -69,6 +169,17 @@ func (v *Visitor) Visit(node ast.Node) ast.Visitor {
 	return nil
 }
 
+// child creates Visitor to format the child node of v's node.
+func (v *Visitor) child() *Visitor { return &Visitor{folded: v.folded} }
+
+// endPos returns the original end position of the formatted expression.
+func (v *Visitor) endPos() int {
+	if v.end != 0 {
+		return v.end
+	}
+	return v.pos + len(v.value)
+}
+
 func createSpacer(n int) string {
 	if n <= 0 {
 		return ""
@@ -83,6 +83,58 @@ func TestVisit(t *testing.T) {
 			val: "1 + 2",
 			pos: 17,
 		},
+		{
+			name: "visit call pos 1",
+			in: &ast.CallExpr{
+				Fun: &ast.Ident{
+					Name:    "max",
+					NamePos: 1,
+				},
+				Lparen: 4,
+				Args: []ast.Expr{
+					&ast.BasicLit{Kind: token.INT, Value: "1", ValuePos: 5},
+					&ast.BasicLit{Kind: token.INT, Value: "2", ValuePos: 8},
+				},
+				Rparen: 9,
+			},
+			val: "max(1, 2)",
+			pos: 1,
+		},
+		{
+			name: "visit index pos 1",
+			in: &ast.IndexExpr{
+				X:      &ast.Ident{Name: "s", NamePos: 1},
+				Lbrack: 2,
+				Index:  &ast.BasicLit{Kind: token.INT, Value: "0", ValuePos: 3},
+				Rbrack: 4,
+			},
+			val: "s[0]",
+			pos: 1,
+		},
+		{
+			name: "visit slice pos 1",
+			in: &ast.SliceExpr{
+				X:      &ast.Ident{Name: "s", NamePos: 1},
+				Lbrack: 2,
+				Low:    &ast.BasicLit{Kind: token.INT, Value: "1", ValuePos: 3},
+				Rbrack: 5,
+			},
+			val: "s[1:]",
+			pos: 1,
+		},
+		{
+			name: "visit 3-index slice pos 1",
+			in: &ast.SliceExpr{
+				X:      &ast.Ident{Name: "s", NamePos: 1},
+				Lbrack: 2,
+				High:   &ast.BasicLit{Kind: token.INT, Value: "1", ValuePos: 4},
+				Max:    &ast.BasicLit{Kind: token.INT, Value: "2", ValuePos: 6},
+				Slice3: true,
+				Rbrack: 7,
+			},
+			val: "s[:1:2]",
+			pos: 1,
+		},
 	}
 
 	for _, tc := range tt {
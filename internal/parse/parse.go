@@ -0,0 +1,508 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"go/ast"
+	"go/token"
+	"unicode/utf8"
+)
+
+// maxDepth is the maximum nesting depth of unary and parenthesized expressions, deeper expression is left to go/parser.
+const maxDepth = 1000
+
+// Expr parses src into the same ast.Expr as go/parser's ParseExpr (including the positions) if src only contains
+// literals, identifiers, parentheses, unary operators [+, -, !, ^] and binary operators. Otherwise, including when
+// src is not a valid expression, it returns false so the caller can fall back to go/parser which reports the error.
+func Expr(src string) (ast.Expr, bool) {
+	p := parser{scanner: scanner{src: src}}
+	p.next()
+	x := p.parseBinary(token.LowestPrec + 1)
+	if p.failed || p.tok != token.EOF {
+		return nil, false
+	}
+	return x, true
+}
+
+// parser is a recursive descent parser following go/parser's precedence rules.
+type parser struct {
+	scanner
+	failed bool
+	depth  int
+	arena  arena
+}
+
+func (p *parser) next() {
+	p.scan()
+	if p.tok == token.ILLEGAL {
+		p.failed = true
+	}
+}
+
+func (p *parser) parseBinary(prec int) ast.Expr {
+	x := p.parseUnary()
+	for !p.failed {
+		op := p.tok
+		oprec := op.Precedence()
+		if oprec < prec {
+			return x
+		}
+		pos := p.pos
+		p.next()
+		y := p.parseBinary(oprec + 1)
+		if p.failed {
+			return nil
+		}
+		b := p.arena.newBinary()
+		b.X, b.OpPos, b.Op, b.Y = x, pos, op, y
+		x = b
+	}
+	return nil
+}
+
+func (p *parser) parseUnary() ast.Expr {
+	if p.depth++; p.depth > maxDepth {
+		p.failed = true
+		return nil
+	}
+
+	var x ast.Expr
+	switch p.tok {
+	case token.ADD, token.SUB, token.NOT, token.XOR:
+		op, pos := p.tok, p.pos
+		p.next()
+		if y := p.parseUnary(); !p.failed {
+			u := p.arena.newUnary()
+			u.OpPos, u.Op, u.X = pos, op, y
+			x = u
+		}
+	default:
+		x = p.parseOperand()
+	}
+
+	p.depth--
+	return x
+}
+
+func (p *parser) parseOperand() ast.Expr {
+	switch p.tok {
+	case token.IDENT:
+		ident := p.arena.newIdent()
+		ident.NamePos, ident.Name = p.pos, p.lit
+		p.next()
+		return ident
+	case token.INT, token.FLOAT, token.IMAG, token.CHAR, token.STRING:
+		basicLit := p.arena.newBasicLit()
+		basicLit.ValuePos, basicLit.Kind, basicLit.Value = p.pos, p.tok, p.lit
+		p.next()
+		return basicLit
+	case token.LPAREN:
+		lparen := p.pos
+		p.next()
+		x := p.parseBinary(token.LowestPrec + 1)
+		if p.failed || p.tok != token.RPAREN {
+			p.failed = true
+			return nil
+		}
+		paren := p.arena.newParen()
+		paren.Lparen, paren.X, paren.Rparen = lparen, x, p.pos
+		p.next()
+		return paren
+	}
+	p.failed = true // including unary "*", "&" and "<-".
+	return nil
+}
+
+// arena allocates the nodes in chunks rather than one by one. The chunks are not reused since the nodes are kept by
+// the caller, so parsing still allocates.
+type arena struct {
+	idents    []ast.Ident
+	basicLits []ast.BasicLit
+	parens    []ast.ParenExpr
+	unaries   []ast.UnaryExpr
+	binaries  []ast.BinaryExpr
+}
+
+const chunkSize = 8
+
+func (a *arena) newIdent() *ast.Ident {
+	if len(a.idents) == cap(a.idents) {
+		a.idents = make([]ast.Ident, 0, chunkSize)
+	}
+	a.idents = a.idents[:len(a.idents)+1]
+	return &a.idents[len(a.idents)-1]
+}
+
+func (a *arena) newBasicLit() *ast.BasicLit {
+	if len(a.basicLits) == cap(a.basicLits) {
+		a.basicLits = make([]ast.BasicLit, 0, chunkSize)
+	}
+	a.basicLits = a.basicLits[:len(a.basicLits)+1]
+	return &a.basicLits[len(a.basicLits)-1]
+}
+
+func (a *arena) newParen() *ast.ParenExpr {
+	if len(a.parens) == cap(a.parens) {
+		a.parens = make([]ast.ParenExpr, 0, chunkSize)
+	}
+	a.parens = a.parens[:len(a.parens)+1]
+	return &a.parens[len(a.parens)-1]
+}
+
+func (a *arena) newUnary() *ast.UnaryExpr {
+	if len(a.unaries) == cap(a.unaries) {
+		a.unaries = make([]ast.UnaryExpr, 0, chunkSize)
+	}
+	a.unaries = a.unaries[:len(a.unaries)+1]
+	return &a.unaries[len(a.unaries)-1]
+}
+
+func (a *arena) newBinary() *ast.BinaryExpr {
+	if len(a.binaries) == cap(a.binaries) {
+		a.binaries = make([]ast.BinaryExpr, 0, chunkSize)
+	}
+	a.binaries = a.binaries[:len(a.binaries)+1]
+	return &a.binaries[len(a.binaries)-1]
+}
+
+// scanner tokenizes src the same way as go/scanner without allocation, the literals are slices of src.
+// Any token that is not supported, including the invalid one, is token.ILLEGAL.
+type scanner struct {
+	src  string
+	off  int  // offset of the next byte.
+	semi bool // a newline after the current token would insert a semicolon, ending the expression.
+
+	tok token.Token
+	pos token.Pos // go/parser's position: the offset starting at 1.
+	lit string
+}
+
+func (s *scanner) scan() {
+	for s.off < len(s.src) {
+		c := s.src[s.off]
+		if c == '\n' && s.semi {
+			// go/parser only accepts the automatically inserted semicolon at the end of the expression.
+			for s.off < len(s.src) && isSpace(s.src[s.off]) {
+				s.off++
+			}
+			if s.off < len(s.src) {
+				s.tok = token.ILLEGAL
+				return
+			}
+			break
+		}
+		if !isSpace(c) {
+			break
+		}
+		s.off++
+	}
+
+	start := s.off
+	s.pos = token.Pos(start + 1)
+	s.lit = ""
+	if s.off >= len(s.src) {
+		s.tok = token.EOF
+		return
+	}
+
+	c := s.src[s.off]
+	s.semi = false
+	switch {
+	case isLetter(c):
+		for s.off < len(s.src) && (isLetter(s.src[s.off]) || isDigit(s.src[s.off])) {
+			s.off++
+		}
+		s.lit = s.src[start:s.off]
+		s.tok = token.IDENT
+		if (s.off < len(s.src) && s.src[s.off] >= utf8.RuneSelf) || token.Lookup(s.lit).IsKeyword() {
+			s.tok = token.ILLEGAL
+		}
+		s.semi = true
+		return
+	case isDigit(c) || (c == '.' && s.off+1 < len(s.src) && isDigit(s.src[s.off+1])):
+		s.tok = s.scanNumber()
+	case c == '"':
+		s.tok = s.scanString()
+	case c == '`':
+		s.tok = s.scanRawString()
+	case c == '\'':
+		s.tok = s.scanChar()
+	default:
+		s.off++
+		s.tok = s.scanOperator(c)
+	}
+	s.lit = s.src[start:s.off]
+	s.semi = s.tok != token.ILLEGAL && s.tok.IsLiteral() || s.tok == token.RPAREN
+}
+
+func (s *scanner) peek() byte {
+	if s.off < len(s.src) {
+		return s.src[s.off]
+	}
+	return 0
+}
+
+// scanOperator scans the operator starting with c, the longer operator such as "++" and "+=" is token.ILLEGAL.
+func (s *scanner) scanOperator(c byte) token.Token {
+	tok := token.ILLEGAL
+	switch c {
+	case '(':
+		return token.LPAREN
+	case ')':
+		return token.RPAREN
+	case '+':
+		tok = token.ADD
+	case '-':
+		tok = token.SUB
+	case '*':
+		tok = token.MUL
+	case '/':
+		if s.peek() == '/' || s.peek() == '*' { // comment
+			return token.ILLEGAL
+		}
+		tok = token.QUO
+	case '%':
+		tok = token.REM
+	case '^':
+		tok = token.XOR
+	case '!':
+		if s.peek() == '=' {
+			s.off++
+			return token.NEQ
+		}
+		return token.NOT
+	case '=':
+		if s.peek() == '=' {
+			s.off++
+			return token.EQL
+		}
+		return token.ILLEGAL
+	case '<':
+		switch s.peek() {
+		case '=':
+			s.off++
+			return token.LEQ
+		case '<':
+			s.off++
+			tok = token.SHL
+		case '-':
+			return token.ILLEGAL
+		default:
+			return token.LSS
+		}
+	case '>':
+		switch s.peek() {
+		case '=':
+			s.off++
+			return token.GEQ
+		case '>':
+			s.off++
+			tok = token.SHR
+		default:
+			return token.GTR
+		}
+	case '&':
+		switch s.peek() {
+		case '&':
+			s.off++
+			return token.LAND
+		case '^':
+			s.off++
+			tok = token.AND_NOT
+		default:
+			tok = token.AND
+		}
+	case '|':
+		if s.peek() == '|' {
+			s.off++
+			return token.LOR
+		}
+		tok = token.OR
+	default:
+		return token.ILLEGAL
+	}
+
+	// "++", "--" and assignment operators.
+	if next := s.peek(); next == '=' || (next == c && (c == '+' || c == '-')) {
+		return token.ILLEGAL
+	}
+	return tok
+}
+
+// scanNumber scans decimal, hexadecimal, octal and binary numbers. Legacy octal (e.g. 0755), hexadecimal float,
+// digit separator and imaginary number with prefix are token.ILLEGAL.
+func (s *scanner) scanNumber() token.Token {
+	start := s.off
+	tok := token.INT
+
+	if s.src[s.off] == '0' && s.off+1 < len(s.src) {
+		var isBaseDigit func(c byte) bool
+		switch s.src[s.off+1] {
+		case 'x', 'X':
+			isBaseDigit = isHex
+		case 'o', 'O':
+			isBaseDigit = func(c byte) bool { return c >= '0' && c <= '7' }
+		case 'b', 'B':
+			isBaseDigit = func(c byte) bool { return c == '0' || c == '1' }
+		}
+		if isBaseDigit != nil {
+			s.off += 2
+			digits := s.off
+			for s.off < len(s.src) && isBaseDigit(s.src[s.off]) {
+				s.off++
+			}
+			if s.off == digits || !s.isNumberEnd() {
+				return token.ILLEGAL
+			}
+			return token.INT
+		}
+	}
+
+	for s.off < len(s.src) && isDigit(s.src[s.off]) {
+		s.off++
+	}
+	if s.src[start] == '0' && s.off-start > 1 { // legacy octal
+		return token.ILLEGAL
+	}
+	if s.peek() == '.' {
+		tok = token.FLOAT
+		s.off++
+		for s.off < len(s.src) && isDigit(s.src[s.off]) {
+			s.off++
+		}
+	}
+	if c := s.peek(); c == 'e' || c == 'E' {
+		tok = token.FLOAT
+		s.off++
+		if c := s.peek(); c == '+' || c == '-' {
+			s.off++
+		}
+		digits := s.off
+		for s.off < len(s.src) && isDigit(s.src[s.off]) {
+			s.off++
+		}
+		if s.off == digits {
+			return token.ILLEGAL
+		}
+	}
+	if s.peek() == 'i' {
+		tok = token.IMAG
+		s.off++
+	}
+	if !s.isNumberEnd() {
+		return token.ILLEGAL
+	}
+	return tok
+}
+
+// isNumberEnd reports whether the number ends at the current offset, e.g. "1a", "1_0" and "1.2.3" do not.
+func (s *scanner) isNumberEnd() bool {
+	c := s.peek()
+	return !isLetter(c) && !isDigit(c) && c != '.' && c < utf8.RuneSelf
+}
+
+func (s *scanner) scanString() token.Token {
+	s.off++
+	for s.off < len(s.src) {
+		switch c := s.src[s.off]; c {
+		case '"':
+			s.off++
+			return token.STRING
+		case '\\':
+			if !s.scanEscape('"') {
+				return token.ILLEGAL
+			}
+		case '\n':
+			return token.ILLEGAL
+		default:
+			if !s.scanRune() {
+				return token.ILLEGAL
+			}
+		}
+	}
+	return token.ILLEGAL
+}
+
+func (s *scanner) scanRawString() token.Token {
+	s.off++
+	for s.off < len(s.src) {
+		switch s.src[s.off] {
+		case '`':
+			s.off++
+			return token.STRING
+		case '\r': // go/scanner removes carriage returns from the literal.
+			return token.ILLEGAL
+		default:
+			if !s.scanRune() {
+				return token.ILLEGAL
+			}
+		}
+	}
+	return token.ILLEGAL
+}
+
+func (s *scanner) scanChar() token.Token {
+	s.off++
+	switch c := s.peek(); c {
+	case '\\':
+		if !s.scanEscape('\'') {
+			return token.ILLEGAL
+		}
+	case '\'', '\n':
+		return token.ILLEGAL
+	default:
+		if !s.scanRune() {
+			return token.ILLEGAL
+		}
+	}
+	if s.peek() != '\'' {
+		return token.ILLEGAL
+	}
+	s.off++
+	return token.CHAR
+}
+
+// scanEscape scans a single character escape, e.g. `\n`, the others such as `\x41` and `\u0041` are token.ILLEGAL.
+func (s *scanner) scanEscape(quote byte) bool {
+	s.off++
+	switch c := s.peek(); c {
+	case 'a', 'b', 'f', 'n', 'r', 't', 'v', '\\', quote:
+		s.off++
+		return true
+	}
+	return false
+}
+
+// scanRune scans a valid UTF-8 encoded rune other than NUL and BOM which go/scanner rejects.
+func (s *scanner) scanRune() bool {
+	c := s.src[s.off]
+	if c == 0 {
+		return false
+	}
+	if c < utf8.RuneSelf {
+		s.off++
+		return true
+	}
+	r, size := utf8.DecodeRuneInString(s.src[s.off:])
+	if (r == utf8.RuneError && size == 1) || r == '\uFEFF' {
+		return false
+	}
+	s.off += size
+	return true
+}
+
+func isSpace(c byte) bool  { return c == ' ' || c == '\t' || c == '\n' || c == '\r' }
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' }
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+func isHex(c byte) bool    { return isDigit(c) || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F' }
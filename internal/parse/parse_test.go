@@ -0,0 +1,208 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse_test
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/muktihari/expr/internal/parse"
+)
+
+func TestExpr(t *testing.T) {
+	tt := []struct {
+		in string
+		ok bool
+	}{
+		{in: "1", ok: true},
+		{in: "  price - (price * discount)  ", ok: true},
+		{in: "1 + 2 * 3 - 4 / 5 % 6", ok: true},
+		{in: "a || b && c == d != e < f <= g > h >= i", ok: true},
+		{in: "a | b ^ c &^ d & e << f >> g", ok: true},
+		{in: "-+!^x", ok: true},
+		{in: "- -1", ok: true},
+		{in: "((((1))))", ok: true},
+		{in: "0 + 0x1F + 0XaB + 0o17 + 0O7 + 0b101 + 0B1", ok: true},
+		{in: "1.5 + .5 + 1. + 1e10 + 1.5E-3 + 2e+2 + 0.1", ok: true},
+		{in: "2i + 1.5i + 0i", ok: true},
+		{in: `"abc" + "a\"b\\c\n\t" + "héllo" + ""`, ok: true},
+		{in: "`raw\n\"string\"`", ok: true},
+		{in: `'a' + '\n' + '\'' + 'é'`, ok: true},
+		{in: "true && nil == _x1", ok: true},
+		{in: "1 +\n2", ok: true},
+		{in: "(1\t+\r\n2)\n", ok: true},
+		{in: "a\n", ok: true},
+
+		{in: ""},
+		{in: "1 +"},
+		{in: "(1 + 2"},
+		{in: "1 + 2)"},
+		{in: "1 2"},
+		{in: "1\n+ 2"},
+		{in: "(a\n+ b)"},
+		{in: "1 ++ 2"},
+		{in: "a--b"},
+		{in: "a += 1"},
+		{in: "a = 1"},
+		{in: "a <- b"},
+		{in: "a <<= b"},
+		{in: "a &^= b"},
+		{in: "*p"},
+		{in: "&a"},
+		{in: "a.b"},
+		{in: "f(x)"},
+		{in: "x[0]"},
+		{in: "x[1:]"},
+		{in: "[]int{1}"},
+		{in: "if"},
+		{in: "func"},
+		{in: "1 // comment"},
+		{in: "/* comment */ 1"},
+		{in: "0755"},
+		{in: "09"},
+		{in: "1_000"},
+		{in: "0x1p3"},
+		{in: "0x"},
+		{in: "0x1i"},
+		{in: "1e"},
+		{in: "1.2.3"},
+		{in: "1a"},
+		{in: `"\x41"`},
+		{in: `"\u0041"`},
+		{in: `"\'"`},
+		{in: `'\"'`},
+		{in: `"unterminated`},
+		{in: "\"new\nline\""},
+		{in: "`raw\r`"},
+		{in: "'ab'"},
+		{in: "''"},
+		{in: "héllo"},
+		{in: "\"\xff\""},
+		{in: "\"\x00\""},
+		{in: "\"\uFEFF\""},
+		{in: strings.Repeat("(", 2000) + "1" + strings.Repeat(")", 2000)},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.in, func(t *testing.T) {
+			e, ok := parse.Expr(tc.in)
+			if ok != tc.ok {
+				t.Fatalf("expected ok: %t, got: %t", tc.ok, ok)
+			}
+			if !ok {
+				return
+			}
+			expected, err := parser.ParseExpr(tc.in)
+			if err != nil {
+				t.Fatalf("expected go/parser to succeed, got: %v", err)
+			}
+			if describe(e) != describe(expected) {
+				t.Fatalf("expected: %s, got: %s", describe(expected), describe(e))
+			}
+		})
+	}
+}
+
+// TestExprRandom makes sure that every random expression that is parsed is the same as go/parser's.
+func TestExprRandom(t *testing.T) {
+	fragments := []string{
+		"1", "0", "12", "1.5", ".5", "1e3", "2i", "0x1F", "0b1", "09", "1_0", `"a"`, `"\n"`, "`b`", "'c'", `'\''`,
+		"x", "y1", "_", "true", "if", "é",
+		"+", "-", "*", "/", "%", "&", "|", "^", "&^", "<<", ">>", "&&", "||", "!", "==", "!=", "<", "<=", ">", ">=",
+		"++", "=", "<-", "+=", "(", ")", "(", ")", ".", "[", "]", ",", "//", "/*",
+	}
+	spaces := []string{"", " ", " ", "\t", "\n"}
+
+	r := rand.New(rand.NewSource(1))
+	var parsed int
+	for i := 0; i < 100000; i++ {
+		var sb strings.Builder
+		for n := r.Intn(8) + 1; n > 0; n-- {
+			sb.WriteString(spaces[r.Intn(len(spaces))])
+			sb.WriteString(fragments[r.Intn(len(fragments))])
+		}
+		in := sb.String()
+
+		e, ok := parse.Expr(in)
+		if !ok {
+			continue
+		}
+		parsed++
+		expected, err := parser.ParseExpr(in)
+		if err != nil {
+			t.Fatalf("%q: expected go/parser to succeed, got: %v", in, err)
+		}
+		if describe(e) != describe(expected) {
+			t.Fatalf("%q: expected: %s, got: %s", in, describe(expected), describe(e))
+		}
+	}
+	if parsed == 0 {
+		t.Fatalf("expected some expressions to be parsed")
+	}
+}
+
+// describe describes every node of e including its positions. It is used instead of reflect.DeepEqual since
+// newer go/parser records BasicLit's ValueEnd which is not available in older Go.
+func describe(e ast.Expr) string {
+	var sb strings.Builder
+	ast.Inspect(e, func(node ast.Node) bool {
+		if node == nil {
+			sb.WriteString(")")
+			return false
+		}
+		fmt.Fprintf(&sb, "(%T[%d:%d]", node, node.Pos(), node.End())
+		switch d := node.(type) {
+		case *ast.Ident:
+			fmt.Fprintf(&sb, " %s %v", d.Name, d.Obj)
+		case *ast.BasicLit:
+			fmt.Fprintf(&sb, " %s %s", d.Kind, d.Value)
+		case *ast.ParenExpr:
+			fmt.Fprintf(&sb, " %d %d", d.Lparen, d.Rparen)
+		case *ast.UnaryExpr:
+			fmt.Fprintf(&sb, " %s %d", d.Op, d.OpPos)
+		case *ast.BinaryExpr:
+			fmt.Fprintf(&sb, " %s %d", d.Op, d.OpPos)
+		default:
+			sb.WriteString(" unexpected")
+		}
+		return true
+	})
+	return sb.String()
+}
+
+func BenchmarkExpr(b *testing.B) {
+	const in = "((qty + 2 == 5) && (qty * 2 <= 10)) || (!(qty + 1 == 5) && (2 / qty < 5)) || (qty % 4 == 1)"
+
+	b.Run("go/parser", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := parser.ParseExpr(in); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("parse", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, ok := parse.Expr(in); !ok {
+				b.Fatal("expected ok")
+			}
+		}
+	})
+}
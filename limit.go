@@ -0,0 +1,115 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+)
+
+// WithContext aborts the evaluation once ctx is done, the returned error wraps ctx.Err().
+func WithContext(ctx context.Context) Option {
+	return func(o *options) { o.ctx = ctx }
+}
+
+// WithMaxDepth limits the nesting depth of the evaluated expression, e.g. "((1))" has depth 3.
+// If v is 0, there is no limit.
+func WithMaxDepth(v int) Option {
+	return func(o *options) { o.maxDepth = v }
+}
+
+// WithMaxNodes limits the number of evaluated nodes (literals, identifiers, operations, calls, etc.),
+// e.g. "1 + 2" has 3 nodes. If v is 0, there is no limit.
+func WithMaxNodes(v int) Option {
+	return func(o *options) { o.maxNodes = v }
+}
+
+// WithMaxStringLen limits the length (in bytes) of every string value, including string literal, resolved variable
+// and the result of concatenation or conversion. If v is 0, there is no limit.
+func WithMaxStringLen(v int) Option {
+	return func(o *options) { o.maxStringLen = v }
+}
+
+// WithMaxInputLen limits the length (in bytes) of expr string, it is checked by Compile before parsing and by
+// Visitor using the evaluated expression's position. If v is 0, there is no limit.
+func WithMaxInputLen(v int) Option {
+	return func(o *options) { o.maxInputLen = v }
+}
+
+// budget tracks the resources used by a single evaluation, it is shared by all Visitors of the evaluation.
+type budget struct {
+	depth int
+	nodes int
+}
+
+// limited reports whether the evaluation needs to be tracked by budget.
+func (o *options) limited() bool {
+	return o.ctx != nil || o.maxDepth > 0 || o.maxNodes > 0 || o.maxStringLen > 0 || o.maxInputLen > 0
+}
+
+// visitLimited visits node while tracking the evaluation's budget.
+func (v *Visitor) visitLimited(node ast.Node) {
+	if v.options.budget == nil { // root
+		if err := checkInputLen(int(node.End()-node.Pos()), v.options.maxInputLen, int(node.Pos())); err != nil {
+			v.err = err
+			return
+		}
+		v.options.budget = &budget{}
+		defer func() { v.options.budget = nil }()
+	}
+
+	b := v.options.budget
+	b.depth++
+	b.nodes++
+	defer func() { b.depth-- }()
+
+	if v.options.ctx != nil {
+		if err := v.options.ctx.Err(); err != nil {
+			v.err = &SyntaxError{Msg: "evaluation is aborted", Pos: v.pos, Err: err}
+			return
+		}
+	}
+	if v.options.maxDepth > 0 && b.depth > v.options.maxDepth {
+		v.err = newLimitExceededError(fmt.Sprintf("expression exceeds maximum depth %d", v.options.maxDepth), v.pos)
+		return
+	}
+	if v.options.maxNodes > 0 && b.nodes > v.options.maxNodes {
+		v.err = newLimitExceededError(fmt.Sprintf("expression exceeds maximum number of nodes %d", v.options.maxNodes), v.pos)
+		return
+	}
+
+	v.visit(node)
+
+	if v.err == nil && v.options.maxStringLen > 0 && v.value.Kind() == KindString && len(v.value.String()) > v.options.maxStringLen {
+		v.err = newLimitExceededError(fmt.Sprintf("string length %d exceeds maximum %d",
+			len(v.value.String()), v.options.maxStringLen), v.pos)
+	}
+}
+
+// checkInputLen returns error if n exceeds maxInputLen, 0 means there is no limit.
+func checkInputLen(n, maxInputLen, pos int) error {
+	if maxInputLen > 0 && n > maxInputLen {
+		return newLimitExceededError(fmt.Sprintf("expr string length %d exceeds maximum %d", n, maxInputLen), pos)
+	}
+	return nil
+}
+
+func newLimitExceededError(msg string, pos int) error {
+	return &SyntaxError{
+		Msg: msg,
+		Pos: pos,
+		Err: ErrLimitExceeded,
+	}
+}
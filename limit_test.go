@@ -0,0 +1,111 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"strings"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	env := map[string]interface{}{"name": strings.Repeat("a", 10)}
+
+	tt := []struct {
+		in          string
+		options     []Option
+		expectedStr string
+		expectedErr error
+		expectedPos int
+	}{
+		{in: "((((1))))", options: []Option{WithMaxDepth(5)}, expectedStr: "1"},
+		{in: "((((1))))", options: []Option{WithMaxDepth(4)}, expectedErr: ErrLimitExceeded, expectedPos: 5},
+		{in: "1 + (2 * 3)", options: []Option{WithMaxDepth(3)}, expectedErr: ErrLimitExceeded, expectedPos: 6},
+		{in: "1 + 2 + 3", options: []Option{WithMaxNodes(5)}, expectedStr: "6"},
+		{in: "1 + 2 + 3", options: []Option{WithMaxNodes(4)}, expectedErr: ErrLimitExceeded, expectedPos: 9},
+		{in: "true || 1 + 2 + 3", options: []Option{WithMaxNodes(3)}, expectedStr: "true"}, // only evaluated nodes
+		{in: "iif(true, 1, 1 + 2 + 3)", options: []Option{WithMaxNodes(3)}, expectedStr: "1"},
+		{in: "in(1, []any{1, 2, 3, 4})", options: []Option{WithMaxNodes(6)}, expectedErr: ErrLimitExceeded},
+		{in: `"ab" + "cd"`, options: []Option{WithMaxStringLen(4)}, expectedStr: "abcd"},
+		{in: `"ab" + "cd"`, options: []Option{WithMaxStringLen(3)}, expectedErr: ErrLimitExceeded, expectedPos: 1},
+		{in: `name`, options: []Option{WithMaxStringLen(9)}, expectedErr: ErrLimitExceeded, expectedPos: 1},
+		{in: `len(name)`, options: []Option{WithMaxStringLen(9)}, expectedErr: ErrLimitExceeded, expectedPos: 5},
+		{in: `string(12345)`, options: []Option{WithMaxStringLen(4)}, expectedErr: ErrLimitExceeded, expectedPos: 1},
+		{in: "1 + 2", options: []Option{WithMaxInputLen(5)}, expectedStr: "3"},
+		{in: "1 + 2", options: []Option{WithMaxInputLen(4)}, expectedErr: ErrLimitExceeded, expectedPos: 1},
+		{in: "1 + 2", options: []Option{WithContext(context.Background())}, expectedStr: "3"},
+		{in: "1 + 2", options: []Option{WithContext(canceled)}, expectedErr: context.Canceled, expectedPos: 1},
+	}
+
+	for i, tc := range tt {
+		tc := tc
+		t.Run(fmt.Sprintf("[%d] %s", i, tc.in), func(t *testing.T) {
+			e, err := parser.ParseExpr(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			opts := append([]Option{WithEnv(env), WithFunctions(map[string]Func{
+				"len": {Args: []Kind{KindString}, Return: KindInt, Fn: func(args ...interface{}) (interface{}, error) {
+					return len(args[0].(string)), nil
+				}},
+			})}, tc.options...)
+
+			v := NewVisitor(opts...)
+			for n := 0; n < 2; n++ { // the budget is not carried over when v is reused.
+				v.reset(v.options)
+				ast.Walk(v, e)
+
+				if err := v.Err(); !errors.Is(err, tc.expectedErr) {
+					t.Fatalf("expected err: %v, got: %v", tc.expectedErr, err)
+				}
+				if tc.expectedPos != 0 {
+					var syntaxErr *SyntaxError
+					if !errors.As(v.Err(), &syntaxErr) || syntaxErr.Pos != tc.expectedPos {
+						t.Fatalf("expected pos: %d, got: %v", tc.expectedPos, v.Err())
+					}
+				}
+				if tc.expectedErr != nil {
+					continue
+				}
+				if str := fmt.Sprint(v.ValueAny()); str != tc.expectedStr {
+					t.Fatalf("expected val: %s, got: %s", tc.expectedStr, str)
+				}
+			}
+
+			// Program is limited the same way as Visitor, even though its constant sub-expressions could be folded.
+			p, err := Compile(tc.in, opts...)
+			if err != nil {
+				if !errors.Is(err, tc.expectedErr) {
+					t.Fatalf("compile: expected err: %v, got: %v", tc.expectedErr, err)
+				}
+				return
+			}
+			val, err := p.Eval(nil)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("program: expected err: %v, got: %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr == nil && fmt.Sprint(val) != tc.expectedStr {
+				t.Fatalf("program: expected val: %s, got: %v", tc.expectedStr, val)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+)
+
+// listValue creates list value, list must not be modified afterward.
+func listValue(list []value) value { return value{any: list} }
+
+// visitCompositeLit handles list literal: []any{"US", "CA", "MX"}. The element type is not checked,
+// so []any, []interface{}, []string or []int can be used interchangeably.
+func (v *Visitor) visitCompositeLit(compositeLit *ast.CompositeLit) ast.Visitor {
+	if v.err = validateCompositeLit(compositeLit); v.err != nil {
+		return nil
+	}
+
+	list := make([]value, len(compositeLit.Elts))
+	for i, elt := range compositeLit.Elts {
+		ve := pool.Get().(*Visitor)
+		ve.reset(v.options)
+
+		ve.Visit(elt)
+		list[i], v.err = ve.value, ve.err
+		pool.Put(ve)
+		if v.err != nil {
+			return nil
+		}
+	}
+	v.value = listValue(list)
+	return nil
+}
+
+// checkIn checks in's arguments for Check, the list must be a list or an object.
+func checkIn(c *checker, callExpr *ast.CallExpr, args []checked) checked {
+	switch args[1].kind {
+	case KindList, KindObject, KindAny:
+		return c.variable(KindBoolean)
+	}
+	return c.report(newArgumentError(callExpr, 1, args[1].value, "a list"))
+}
+
+// validateCompositeLit validates that compositeLit is a list literal.
+func validateCompositeLit(compositeLit *ast.CompositeLit) error {
+	arrayType, ok := compositeLit.Type.(*ast.ArrayType)
+	if !ok || arrayType.Len != nil {
+		return newUnsupportedSyntaxError(compositeLit)
+	}
+	for _, elt := range compositeLit.Elts {
+		if _, ok := elt.(*ast.KeyValueExpr); ok {
+			return newUnsupportedSyntaxError(elt)
+		}
+	}
+	return nil
+}
+
+// builtinIn reports whether x is an element of the list: in(x, []any{1, 2, 3}). The list can be a list literal,
+// or a slice or an array from variable. Elements are compared using "==", element that is not comparable with x
+// is treated as not equal.
+func builtinIn(v *Visitor, callExpr *ast.CallExpr) {
+	vx := pool.Get().(*Visitor)
+	defer pool.Put(vx)
+	vx.reset(v.options)
+
+	vx.Visit(callExpr.Args[0])
+	if vx.err != nil {
+		v.err = vx.err
+		return
+	}
+	vx.value = runeAsInt(vx.value)
+
+	vl := pool.Get().(*Visitor)
+	defer pool.Put(vl)
+	vl.reset(v.options)
+
+	vl.Visit(callExpr.Args[1])
+	if vl.err != nil {
+		v.err = vl.err
+		return
+	}
+
+	ve := pool.Get().(*Visitor)
+	defer pool.Put(ve)
+
+	binaryExpr := &ast.BinaryExpr{X: callExpr.Args[0], Op: token.EQL, OpPos: callExpr.Lparen, Y: callExpr.Args[1]}
+	equal := func(elem value) bool {
+		ve.reset(v.options)
+		veq := pool.Get().(*Visitor)
+		veq.reset(v.options)
+		veq.value = elem
+		comparison(ve, vx, veq, binaryExpr)
+		pool.Put(veq)
+		return ve.err == nil && ve.value.Bool()
+	}
+
+	switch vl.value.Kind() {
+	case KindList:
+		for _, elem := range vl.value.List() {
+			if equal(runeAsInt(elem)) {
+				v.value = boolValue(true)
+				return
+			}
+		}
+		v.value = boolValue(false)
+		return
+	case KindObject:
+		rv := indirect(reflect.ValueOf(vl.value.Object()))
+		if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+			for i := 0; i < rv.Len(); i++ {
+				elem, ok := elementValue(rv.Index(i).Interface())
+				if ok && equal(elem) {
+					v.value = boolValue(true)
+					return
+				}
+			}
+			v.value = boolValue(false)
+			return
+		}
+	}
+
+	v.err = newArgumentError(callExpr, 1, vl.value, "a list")
+}
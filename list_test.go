@@ -0,0 +1,109 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"testing"
+)
+
+func TestListAndIn(t *testing.T) {
+	env := map[string]interface{}{
+		"country": "CA",
+		"code":    204,
+		"codes":   []int{200, 201, 204},
+		"tags":    [2]string{"a", "b"},
+		"mixed":   []interface{}{nil, "x", 1.5},
+		"labels":  map[string]string{"env": "prod"},
+	}
+
+	tt := []struct {
+		in           string
+		options      []Option
+		expectedKind Kind
+		expectedStr  string
+		expectedErr  error
+		expectedPos  int
+	}{
+		{in: `in(country, []any{"US", "CA", "MX"})`, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `in(country, []string{"US", "MX"})`, expectedKind: KindBoolean, expectedStr: "false"},
+		{in: `in(code, []int{200, 201, 204})`, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `in(200, []any{200.0})`, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `in(2, []any{"2", true, 1 + 1})`, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `in('a', []any{97})`, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `in(97, []any{'a'})`, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `in(1, []any{})`, expectedKind: KindBoolean, expectedStr: "false"},
+		{in: `in(code, codes)`, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `in("b", tags)`, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `in(1.5, mixed)`, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `in(2, mixed)`, expectedKind: KindBoolean, expectedStr: "false"},
+		{in: `!in(country, []any{"US"}) && code == 204`, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `[]any{1, "a", true}`, expectedKind: KindList, expectedStr: "[1 a true]"},
+		{in: `[]any{1, "a", true}[1]`, expectedKind: KindString, expectedStr: "a"},
+		{in: `[]any{1, "a", true}[3]`, expectedErr: ErrIndexOutOfRange, expectedPos: 21},
+		{in: `[]any{1, "a", true}[3]`, options: []Option{WithMissingAsNil(true)}, expectedKind: KindNil, expectedStr: "<nil>"},
+		{in: `[]any{1, "a"}["a"]`, expectedErr: ErrIndexOperation, expectedPos: 15},
+		{in: `in(country, labels)`, expectedErr: ErrFunctionCall, expectedPos: 13},
+		{in: `in(country, "CA")`, expectedErr: ErrFunctionCall, expectedPos: 13},
+		{in: `in(country)`, expectedErr: ErrFunctionCall, expectedPos: 11},
+		{in: `in(country, codes, codes)`, expectedErr: ErrFunctionCall, expectedPos: 20},
+		{in: `in(codes...)`, expectedErr: ErrFunctionCall, expectedPos: 9},
+		{in: `in(1 + true, codes)`, expectedErr: ErrArithmeticOperation},
+		{in: `in(1, []any{1 + true})`, expectedErr: ErrArithmeticOperation},
+		{in: `[2]int{1, 2}`, expectedErr: ErrUnsupportedSyntax, expectedPos: 1},
+		{in: `[]any{0: 1}`, expectedErr: ErrUnsupportedSyntax, expectedPos: 7},
+		{in: `in(2, []any{1})`, expectedKind: KindBoolean, expectedStr: "true",
+			options: []Option{WithFunctions(map[string]Func{
+				"in": {Args: []Kind{KindInt, KindAny}, Return: KindBoolean, Fn: func(args ...interface{}) (interface{}, error) {
+					return true, nil
+				}},
+			})},
+		},
+	}
+
+	for i, tc := range tt {
+		tc := tc
+		t.Run(fmt.Sprintf("[%d] %s", i, tc.in), func(t *testing.T) {
+			e, err := parser.ParseExpr(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := NewVisitor(append([]Option{WithEnv(env)}, tc.options...)...)
+			ast.Walk(v, e)
+
+			if err := v.Err(); !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected err: %v, got: %v", tc.expectedErr, err)
+			}
+			if tc.expectedPos != 0 {
+				var syntaxErr *SyntaxError
+				if !errors.As(v.Err(), &syntaxErr) || syntaxErr.Pos != tc.expectedPos {
+					t.Fatalf("expected pos: %d, got: %v", tc.expectedPos, v.Err())
+				}
+			}
+			if tc.expectedErr != nil {
+				return
+			}
+			if v.Kind() != tc.expectedKind {
+				t.Fatalf("expected kind: %s, got: %s", tc.expectedKind, v.Kind())
+			}
+			if str := fmt.Sprint(v.ValueAny()); str != tc.expectedStr {
+				t.Fatalf("expected val: %s, got: %s", tc.expectedStr, str)
+			}
+		})
+	}
+}
@@ -21,6 +21,25 @@ import (
 	"github.com/muktihari/expr/internal/conv"
 )
 
+// shortCircuit reports whether the result of logical operation can be determined by vx alone, so Y will not be evaluated:
+// "false && Y" is false and "true || Y" is true. If it returns true, v's value or error has been set.
+func shortCircuit(v, vx *Visitor, binaryExpr *ast.BinaryExpr) bool {
+	if binaryExpr.Op != token.LAND && binaryExpr.Op != token.LOR {
+		return false
+	}
+	if vx.value.Kind() != KindBoolean {
+		v.err = newLogicalNonBooleanError(vx, binaryExpr.X)
+		return true
+	}
+
+	x := vx.value.Bool()
+	if (binaryExpr.Op == token.LAND && !x) || (binaryExpr.Op == token.LOR && x) {
+		v.value = boolValue(x)
+		return true
+	}
+	return false
+}
+
 func logical(v, vx, vy *Visitor, binaryExpr *ast.BinaryExpr) {
 	if vx.value.Kind() != KindBoolean {
 		v.err = newLogicalNonBooleanError(vx, binaryExpr.X)
@@ -42,6 +61,52 @@ func logical(v, vx, vy *Visitor, binaryExpr *ast.BinaryExpr) {
 	v.value = boolValue(x || y) // token.LOR
 }
 
+// builtinIif is a conditional expression: iif(qty > 100, 0.1, 0) is 0.1 if qty > 100, otherwise 0. Only the chosen
+// branch is evaluated and the branches may have different Kind. It is named iif since "if" is Go's keyword.
+func builtinIif(v *Visitor, callExpr *ast.CallExpr) {
+	vc := pool.Get().(*Visitor)
+	defer pool.Put(vc)
+	vc.reset(v.options)
+
+	vc.Visit(callExpr.Args[0])
+	if vc.err != nil {
+		v.err = vc.err
+		return
+	}
+	if vc.value.Kind() != KindBoolean {
+		v.err = newLogicalNonBooleanError(vc, callExpr.Args[0])
+		return
+	}
+
+	branch := callExpr.Args[2]
+	if vc.value.Bool() {
+		branch = callExpr.Args[1]
+	}
+
+	vb := pool.Get().(*Visitor)
+	defer pool.Put(vb)
+	vb.reset(v.options)
+
+	vb.Visit(branch)
+	v.value, v.err = vb.value, vb.err
+}
+
+// checkIif checks iif's condition for Check, the result is KindAny if the branches have different Kind.
+func checkIif(c *checker, callExpr *ast.CallExpr, args []checked) checked {
+	switch {
+	case args[0].kind != KindBoolean && args[0].kind != KindAny:
+		vc := c.visitor(args[0], callExpr.Args[0])
+		defer pool.Put(vc)
+		return c.report(newLogicalNonBooleanError(vc, callExpr.Args[0]))
+	case args[0].constant:
+		if args[0].value.Bool() {
+			return args[1]
+		}
+		return args[2]
+	}
+	return c.variable(commonKind(args[1], args[2]))
+}
+
 func newLogicalNonBooleanError(v *Visitor, e ast.Expr) error {
 	s := conv.FormatExpr(e)
 	return &SyntaxError{
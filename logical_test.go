@@ -17,6 +17,7 @@ import (
 	"errors"
 	"fmt"
 	"go/ast"
+	"go/parser"
 	"go/token"
 	"testing"
 )
@@ -89,3 +90,85 @@ func TestLogical(t *testing.T) {
 		}
 	}
 }
+
+func TestShortCircuit(t *testing.T) {
+	tt := []struct {
+		vx            *Visitor
+		op            token.Token
+		expected      bool
+		expectedValue value
+		expectedErr   error
+	}{
+		{vx: &Visitor{value: boolValue(false)}, op: token.LAND, expected: true, expectedValue: boolValue(false)},
+		{vx: &Visitor{value: boolValue(true)}, op: token.LAND, expected: false},
+		{vx: &Visitor{value: boolValue(true)}, op: token.LOR, expected: true, expectedValue: boolValue(true)},
+		{vx: &Visitor{value: boolValue(false)}, op: token.LOR, expected: false},
+		{vx: &Visitor{value: stringValue("1")}, op: token.LOR, expected: true, expectedErr: ErrLogicalOperation},
+		{vx: &Visitor{value: boolValue(false)}, op: token.ADD, expected: false},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(fmt.Sprintf("%v %s Y", tc.vx.value.Any(), tc.op), func(t *testing.T) {
+			v := &Visitor{}
+			be := &ast.BinaryExpr{X: &ast.BasicLit{}, Op: tc.op}
+			if ok := shortCircuit(v, tc.vx, be); ok != tc.expected {
+				t.Fatalf("expected: %t, got: %t", tc.expected, ok)
+			}
+			if !errors.Is(v.err, tc.expectedErr) {
+				t.Fatalf("expected err: %v, got: %v", tc.expectedErr, v.err)
+			}
+			if v.value.Any() != tc.expectedValue.Any() {
+				t.Fatalf("expected value: %v, got: %v", tc.expectedValue.Any(), v.value.Any())
+			}
+		})
+	}
+}
+
+func TestIif(t *testing.T) {
+	tt := []struct {
+		in          string
+		expectedStr string
+		expectedErr error
+		expectedPos int
+	}{
+		{in: `iif(qty > 100, 0.1, 0)`, expectedStr: "0.1"},
+		{in: `iif(qty > 1000, 0.1, 0)`, expectedStr: "0"},
+		{in: `iif(qty > 1000, 0.2, iif(qty > 100, 0.1, 0))`, expectedStr: "0.1"},
+		{in: `iif(qty > 100, "bulk", 1)`, expectedStr: "bulk"},
+		{in: `iif(qty > 100, 1, 1 + true)`, expectedStr: "1"}, // lazy
+		{in: `iif(qty < 100, 1, 1 + true)`, expectedErr: ErrArithmeticOperation},
+		{in: `iif(qty, 1, 0)`, expectedErr: ErrLogicalOperation, expectedPos: 5},
+		{in: `iif(1 + true, 1, 0)`, expectedErr: ErrArithmeticOperation},
+		{in: `iif(true, 1)`, expectedErr: ErrFunctionCall, expectedPos: 12},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.in, func(t *testing.T) {
+			e, err := parser.ParseExpr(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := NewVisitor(WithEnv(map[string]interface{}{"qty": 150}))
+			ast.Walk(v, e)
+
+			if err := v.Err(); !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected err: %v, got: %v", tc.expectedErr, err)
+			}
+			if tc.expectedPos != 0 {
+				var syntaxErr *SyntaxError
+				if !errors.As(v.Err(), &syntaxErr) || syntaxErr.Pos != tc.expectedPos {
+					t.Fatalf("expected pos: %d, got: %v", tc.expectedPos, v.Err())
+				}
+			}
+			if tc.expectedErr != nil {
+				return
+			}
+			if str := fmt.Sprint(v.ValueAny()); str != tc.expectedStr {
+				t.Fatalf("expected val: %s, got: %s", tc.expectedStr, str)
+			}
+		})
+	}
+}
@@ -0,0 +1,513 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/cmplx"
+)
+
+// WithMath registers standard math functions and constants, it can be overridden by WithFunctions and WithEnv.
+//
+// - Functions:
+//   - Integer preserving: [abs, min, max, floor, ceil, trunc, round] (e.g. floor(2) is an integer)
+//   - round(x, digits) rounds half away from zero, digits is optional and can be negative: round(1234.5, -2) -> 1200
+//   - Real: [sqrt, cbrt, pow, exp, log, log2, log10, hypot, sin, cos, tan, asin, acos, atan]
+//
+// - Constants: [pi, e]
+//
+// A number out of the function's domain, e.g. sqrt(-1), is an error wrapping ErrArithmeticOperation,
+// unless the argument is a complex number or NumericTypeComplex is used: sqrt(-1) -> (0+1i).
+//
+// Integer preserving functions keep *big.Int, *big.Float and Decimal exact, the others compute them as float64.
+// pow of integers is an integer unless it overflows int64, e.g. pow(2, 64) is a float, while abs(MinInt64) is an error
+// wrapping ErrIntegerOverflow.
+func WithMath() Option {
+	return func(o *options) {
+		WithFunctions(mathFunctions)(o)
+		o.constants = mathConstants
+	}
+}
+
+var mathConstants = map[string]value{
+	"pi": float64Value(math.Pi),
+	"e":  float64Value(math.E),
+}
+
+var mathFunctions = map[string]Func{
+	"abs":   {Args: []Kind{KindAny}, Return: KindAny, Fn: mathAbs},
+	"min":   {Args: []Kind{KindAny, KindAny}, Variadic: true, Return: KindAny, Fn: mathMin},
+	"max":   {Args: []Kind{KindAny, KindAny}, Variadic: true, Return: KindAny, Fn: mathMax},
+	"floor": {Args: []Kind{KindAny}, Return: KindAny, Fn: mathRounding("floor", math.Floor, big.ToNegativeInf)},
+	"ceil":  {Args: []Kind{KindAny}, Return: KindAny, Fn: mathRounding("ceil", math.Ceil, big.ToPositiveInf)},
+	"trunc": {Args: []Kind{KindAny}, Return: KindAny, Fn: mathRounding("trunc", math.Trunc, big.ToZero)},
+	"round": {Args: []Kind{KindAny, KindInt}, Optional: 1, Return: KindAny, Fn: mathRound},
+	"sqrt":  {Args: []Kind{KindAny}, Return: KindAny, Fn: mathReal("sqrt", math.Sqrt, cmplx.Sqrt)},
+	"cbrt":  {Args: []Kind{KindAny}, Return: KindAny, Fn: mathReal("cbrt", math.Cbrt, cmplxCbrt)},
+	"exp":   {Args: []Kind{KindAny}, Return: KindAny, Fn: mathReal("exp", math.Exp, cmplx.Exp)},
+	"log":   {Args: []Kind{KindAny}, Return: KindAny, Fn: mathReal("log", math.Log, cmplx.Log)},
+	"log2":  {Args: []Kind{KindAny}, Return: KindAny, Fn: mathReal("log2", math.Log2, cmplxLog2)},
+	"log10": {Args: []Kind{KindAny}, Return: KindAny, Fn: mathReal("log10", math.Log10, cmplx.Log10)},
+	"sin":   {Args: []Kind{KindAny}, Return: KindAny, Fn: mathReal("sin", math.Sin, cmplx.Sin)},
+	"cos":   {Args: []Kind{KindAny}, Return: KindAny, Fn: mathReal("cos", math.Cos, cmplx.Cos)},
+	"tan":   {Args: []Kind{KindAny}, Return: KindAny, Fn: mathReal("tan", math.Tan, cmplx.Tan)},
+	"asin":  {Args: []Kind{KindAny}, Return: KindAny, Fn: mathReal("asin", math.Asin, cmplx.Asin)},
+	"acos":  {Args: []Kind{KindAny}, Return: KindAny, Fn: mathReal("acos", math.Acos, cmplx.Acos)},
+	"atan":  {Args: []Kind{KindAny}, Return: KindAny, Fn: mathReal("atan", math.Atan, cmplx.Atan)},
+	"pow":   {Args: []Kind{KindAny, KindAny}, Return: KindAny, Fn: mathPow},
+	"hypot": {Args: []Kind{KindFloat, KindFloat}, Return: KindFloat, Fn: mathHypot},
+}
+
+func newMathDomainError(name string, args ...interface{}) error {
+	return fmt.Errorf("%s%v is out of the function's domain: %w", name, args, ErrArithmeticOperation)
+}
+
+func newMathNonRealError(name string, x interface{}) error {
+	return fmt.Errorf("%s: %v is not a real number: %w", name, x, ErrArithmeticOperation)
+}
+
+func newRoundOverflowError(x, digits int64) error {
+	return fmt.Errorf("round: %d rounded to %d digits overflows int64: %w", x, digits, ErrIntegerOverflow)
+}
+
+func mathAbs(args ...interface{}) (interface{}, error) {
+	switch x := args[0].(type) {
+	case int64:
+		if x == math.MinInt64 {
+			return nil, fmt.Errorf("abs: %d overflows int64: %w", x, ErrIntegerOverflow)
+		}
+		if x < 0 {
+			return -x, nil
+		}
+		return x, nil
+	case uint64:
+		return x, nil
+	case float64:
+		return math.Abs(x), nil
+	case complex128:
+		return cmplx.Abs(x), nil
+	case *big.Int:
+		return new(big.Int).Abs(x), nil
+	case *big.Float:
+		return new(big.Float).Abs(x), nil
+	case Decimal:
+		if x.Sign() < 0 {
+			return Decimal{unscaled: new(big.Int).Neg(x.int()), scale: x.scale}, nil
+		}
+		return x, nil
+	}
+	return nil, newMathNonRealError("abs", args[0])
+}
+
+func mathMin(args ...interface{}) (interface{}, error) { return mathExtremum("min", args, false) }
+
+func mathMax(args ...interface{}) (interface{}, error) { return mathExtremum("max", args, true) }
+
+// mathExtremum returns the smallest argument, or the largest if isMax. The result is an integer only if
+// all arguments are integers, if any of the arguments is NaN, the result is NaN.
+func mathExtremum(name string, args []interface{}, isMax bool) (interface{}, error) {
+	allInt, anyComplex, anyBig := true, false, false
+	for _, arg := range args {
+		switch x := arg.(type) {
+		case int64:
+		case float64:
+			allInt = false
+		case uint64, *big.Int, *big.Float, Decimal:
+			anyBig = true
+		case complex128:
+			if imag(x) != 0 {
+				return nil, newMathNonRealError(name, arg)
+			}
+			allInt, anyComplex = false, true
+		default:
+			return nil, newMathNonRealError(name, arg)
+		}
+	}
+
+	if anyBig && !anyComplex {
+		return bigExtremum(args, isMax), nil
+	}
+
+	if allInt {
+		m := args[0].(int64)
+		for _, arg := range args[1:] {
+			if x := arg.(int64); (isMax && x > m) || (!isMax && x < m) {
+				m = x
+			}
+		}
+		return m, nil
+	}
+
+	m := toFloat64(args[0])
+	for _, arg := range args[1:] {
+		if x := toFloat64(arg); (isMax && x > m) || (!isMax && x < m) || math.IsNaN(x) {
+			m = x
+		}
+	}
+	if anyComplex {
+		return complex(m, 0), nil
+	}
+	return m, nil
+}
+
+// bigExtremum returns the smallest or the largest argument as it is, the arguments are compared exactly.
+func bigExtremum(args []interface{}, isMax bool) interface{} {
+	m, mv := args[0], bigFloatOf(args[0])
+	for _, arg := range args[1:] {
+		xv := bigFloatOf(arg)
+		if xv == nil { // NaN
+			return math.NaN()
+		}
+		if mv == nil {
+			continue
+		}
+		if cmp := xv.Cmp(mv); (isMax && cmp > 0) || (!isMax && cmp < 0) {
+			m, mv = arg, xv
+		}
+	}
+	if mv == nil {
+		return math.NaN()
+	}
+	return m
+}
+
+// bigFloatOf converts x into *big.Float, it returns nil if x is NaN.
+func bigFloatOf(x interface{}) *big.Float {
+	switch val := x.(type) {
+	case int64:
+		return new(big.Float).SetInt64(val)
+	case uint64:
+		return new(big.Float).SetUint64(val)
+	case float64:
+		if math.IsNaN(val) {
+			return nil
+		}
+		return big.NewFloat(val)
+	case complex128:
+		return big.NewFloat(real(val))
+	case *big.Int:
+		return new(big.Float).SetInt(val)
+	case *big.Float:
+		return val
+	case Decimal:
+		return new(big.Float).SetPrec(DefaultBigFloatPrecision).SetRat(val.Rat())
+	}
+	return nil
+}
+
+func toFloat64(x interface{}) float64 {
+	switch val := x.(type) {
+	case int64:
+		return float64(val)
+	case uint64:
+		return float64(val)
+	case float64:
+		return val
+	case complex128:
+		return real(val)
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(val).Float64()
+		return f
+	case *big.Float:
+		f, _ := val.Float64()
+		return f
+	case Decimal:
+		return val.Float64()
+	}
+	return 0
+}
+
+func toComplex128(x interface{}) complex128 {
+	switch val := x.(type) {
+	case int64:
+		return complex(float64(val), 0)
+	case float64:
+		return complex(val, 0)
+	case complex128:
+		return val
+	case uint64, *big.Int, *big.Float, Decimal:
+		return complex(toFloat64(val), 0)
+	}
+	return 0
+}
+
+// mathRounding creates rounding function that keeps integer argument as it is.
+func mathRounding(name string, fn func(float64) float64, mode big.RoundingMode) func(args ...interface{}) (interface{}, error) {
+	return func(args ...interface{}) (interface{}, error) {
+		switch x := args[0].(type) {
+		case int64, uint64, *big.Int:
+			return x, nil
+		case *big.Float:
+			return roundBigFloatToInt(x, mode), nil
+		case Decimal:
+			return roundDecimalToInt(x, mode), nil
+		case float64:
+			return fn(x), nil
+		case complex128:
+			if imag(x) == 0 {
+				return complex(fn(real(x)), 0), nil
+			}
+		}
+		return nil, newMathNonRealError(name, args[0])
+	}
+}
+
+func mathRound(args ...interface{}) (interface{}, error) {
+	var digits int64
+	if len(args) == 2 {
+		digits = args[1].(int64)
+	}
+
+	switch x := args[0].(type) {
+	case int64:
+		if digits >= 0 {
+			return x, nil
+		}
+		if digits < -18 { // 10^19 overflows int64, so does x that is rounded up to it.
+			if digits == -19 && (x >= 5e18 || x <= -5e18) {
+				return nil, newRoundOverflowError(x, digits)
+			}
+			return int64(0), nil
+		}
+		p := int64(math.Pow10(int(-digits)))
+		q, rem := x/p, x%p
+		if rem >= p/2 {
+			q++
+		} else if rem <= -p/2 {
+			q--
+		}
+		r, overflow := mulInt64(q, p)
+		if overflow {
+			return nil, newRoundOverflowError(x, digits)
+		}
+		return r, nil
+	case float64:
+		return roundFloat(x, digits), nil
+	case complex128:
+		if imag(x) == 0 {
+			return complex(roundFloat(real(x), digits), 0), nil
+		}
+	case uint64:
+		r := roundBigInt(new(big.Int).SetUint64(x), digits)
+		if r.IsUint64() {
+			return r.Uint64(), nil
+		}
+		return r, nil
+	case *big.Int:
+		return roundBigInt(x, digits), nil
+	case *big.Float:
+		return roundBigFloat(x, digits), nil
+	case Decimal:
+		return roundDecimal(x, digits), nil
+	}
+	return nil, newMathNonRealError("round", args[0])
+}
+
+// roundBigFloatToInt rounds x into an integer using mode, the result is still a *big.Float.
+func roundBigFloatToInt(x *big.Float, mode big.RoundingMode) *big.Float {
+	if x.IsInf() || x.IsInt() {
+		return x
+	}
+	r := new(big.Float).SetPrec(x.Prec())
+	exp := x.MantExp(nil) // |x| < 2^exp
+	if exp <= 0 {         // |x| < 1
+		switch {
+		case mode == big.ToNegativeInf && x.Sign() < 0:
+			return r.SetInt64(-1)
+		case mode == big.ToPositiveInf && x.Sign() > 0:
+			return r.SetInt64(1)
+		}
+		return r
+	}
+	z := new(big.Float).SetMode(mode).SetPrec(uint(exp)).Set(x) // only the integer bits remain.
+	return r.Set(z)
+}
+
+// roundDecimalToInt rounds x into an integer using mode, the result's scale is 0.
+func roundDecimalToInt(x Decimal, mode big.RoundingMode) Decimal {
+	if x.scale == 0 {
+		return x
+	}
+	z := x.round(0, RoundDown)
+	if x.IsInt() {
+		return z
+	}
+	switch {
+	case mode == big.ToNegativeInf && x.Sign() < 0:
+		z.unscaled.Sub(z.unscaled, bigOne)
+	case mode == big.ToPositiveInf && x.Sign() > 0:
+		z.unscaled.Add(z.unscaled, bigOne)
+	}
+	return z
+}
+
+// roundDecimal rounds x half away from zero to the given digits.
+func roundDecimal(x Decimal, digits int64) Decimal {
+	if digits >= int64(x.scale) {
+		return x
+	}
+	if digits >= 0 {
+		return x.round(int(digits), RoundHalfUp)
+	}
+	if float64(-digits) > float64(x.int().BitLen())*math.Log10(2)+1-float64(x.scale) { // |x| < 10^-digits / 2
+		return Decimal{}
+	}
+	q := roundQuo(x.int(), pow10(x.scale-int(digits)), RoundHalfUp)
+	return newDecimal(q, int(digits))
+}
+
+var bigTen = big.NewInt(10)
+
+// roundBigInt rounds x half away from zero to the given negative digits.
+func roundBigInt(x *big.Int, digits int64) *big.Int {
+	if digits >= 0 {
+		return x
+	}
+	if float64(-digits) > float64(x.BitLen())*math.Log10(2)+1 { // |x| < 10^-digits / 2
+		return new(big.Int)
+	}
+	p := new(big.Int).Exp(bigTen, big.NewInt(-digits), nil)
+	half := new(big.Int).Quo(p, big.NewInt(2))
+	r := new(big.Int)
+	if x.Sign() < 0 {
+		r.Sub(x, half)
+	} else {
+		r.Add(x, half)
+	}
+	r.Quo(r, p)
+	return r.Mul(r, p)
+}
+
+// roundBigFloat rounds x half away from zero to the given digits.
+func roundBigFloat(x *big.Float, digits int64) *big.Float {
+	if x.IsInf() || x.Sign() == 0 {
+		return x
+	}
+	exp := x.MantExp(nil) // |x| < 2^exp
+	if digits >= 0 && float64(digits) > float64(int64(x.Prec())-int64(exp))*math.Log10(2)+1 {
+		return x // x has no more digits to be rounded.
+	}
+	if digits < 0 && float64(-digits) > float64(exp)*math.Log10(2)+1 {
+		return new(big.Float).SetPrec(x.Prec()) // all digits are rounded.
+	}
+
+	n := digits
+	if n < 0 {
+		n = -n
+	}
+	p := new(big.Float).SetInt(new(big.Int).Exp(bigTen, big.NewInt(n), nil))
+	prec := x.Prec() + p.MinPrec()
+
+	r := new(big.Float).SetPrec(prec)
+	if digits >= 0 {
+		r.Mul(x, p)
+	} else {
+		r.Quo(x, p)
+	}
+	half := big.NewFloat(0.5)
+	if r.Sign() < 0 {
+		half.Neg(half)
+	}
+	i, _ := r.Add(r, half).Int(nil) // truncated
+	r.SetInt(i)
+	if digits >= 0 {
+		r.Quo(r, p)
+	} else {
+		r.Mul(r, p)
+	}
+	return r.SetPrec(x.Prec())
+}
+
+func roundFloat(x float64, digits int64) float64 {
+	if digits == 0 {
+		return math.Round(x)
+	}
+	p := math.Pow10(int(digits))
+	if p == 0 {
+		return 0 // all digits are rounded.
+	}
+	if r := math.Round(x*p) / p; !math.IsInf(r, 0) && !math.IsNaN(r) {
+		return r
+	}
+	return x // x*p overflows, x has no more digits to be rounded.
+}
+
+// mathReal creates function over real numbers, it uses its complex counterpart if the argument is a complex number.
+func mathReal(name string, fn func(float64) float64, fnc func(complex128) complex128) func(args ...interface{}) (interface{}, error) {
+	return func(args ...interface{}) (interface{}, error) {
+		if x, ok := args[0].(complex128); ok {
+			return fnc(x), nil
+		}
+		x := toFloat64(args[0])
+		r := fn(x)
+		if math.IsNaN(r) && !math.IsNaN(x) {
+			return nil, newMathDomainError(name, args...)
+		}
+		return r, nil
+	}
+}
+
+func cmplxCbrt(x complex128) complex128 { return cmplx.Pow(x, 1.0/3) }
+
+func cmplxLog2(x complex128) complex128 { return cmplx.Log(x) / math.Ln2 }
+
+func mathPow(args ...interface{}) (interface{}, error) {
+	_, xc := args[0].(complex128)
+	_, yc := args[1].(complex128)
+	if xc || yc {
+		return cmplx.Pow(toComplex128(args[0]), toComplex128(args[1])), nil
+	}
+
+	x, xok := args[0].(int64)
+	y, yok := args[1].(int64)
+	if xok && yok && y >= 0 {
+		if r, ok := powInt64(x, y); ok {
+			return r, nil
+		}
+		// overflows int64, calculate it as float64 below.
+	}
+
+	fx, fy := toFloat64(args[0]), toFloat64(args[1])
+	r := math.Pow(fx, fy)
+	if math.IsNaN(r) && !math.IsNaN(fx) && !math.IsNaN(fy) {
+		return nil, newMathDomainError("pow", args...)
+	}
+	return r, nil
+}
+
+// powInt64 calculates x**y using exponentiation by squaring, y must not be negative.
+// It returns false if the result overflows int64.
+func powInt64(x, y int64) (int64, bool) {
+	r := int64(1)
+	for {
+		var overflow bool
+		if y&1 == 1 {
+			if r, overflow = mulInt64(r, x); overflow {
+				return 0, false
+			}
+		}
+		if y >>= 1; y == 0 {
+			return r, true
+		}
+		if x, overflow = mulInt64(x, x); overflow { // |r * x| >= |x| for the rest of y's bits.
+			return 0, false
+		}
+	}
+}
+
+func mathHypot(args ...interface{}) (interface{}, error) {
+	return math.Hypot(args[0].(float64), args[1].(float64)), nil
+}
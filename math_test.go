@@ -0,0 +1,174 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestWithMath(t *testing.T) {
+	tt := []struct {
+		in            string
+		numericType   NumericType
+		expectedValue value
+		expectedErr   error
+	}{
+		{in: "abs(-2)", expectedValue: int64Value(2)},
+		{in: "abs(-2.5)", expectedValue: float64Value(2.5)},
+		{in: "abs(3+4i)", expectedValue: float64Value(5)},
+		{in: "abs(true)", expectedErr: ErrArithmeticOperation},
+		{in: "abs(-9223372036854775807 - 1)", numericType: NumericTypeInt, expectedErr: ErrIntegerOverflow},
+		{in: "abs(-9223372036854775807)", numericType: NumericTypeInt, expectedValue: int64Value(math.MaxInt64)},
+		{in: "min(3, 1, 2)", expectedValue: int64Value(1)},
+		{in: "min(3, 1.5, 2)", expectedValue: float64Value(1.5)},
+		{in: "max(3, 1, 2)", expectedValue: int64Value(3)},
+		{in: "max(3, 4.5)", expectedValue: float64Value(4.5)},
+		{in: "max(9007199254740993, 9007199254740992)", expectedValue: int64Value(9007199254740993)},
+		{in: "max(1, 2)", numericType: NumericTypeComplex, expectedValue: complex128Value(2)},
+		{in: "max(1, 2i)", expectedErr: ErrArithmeticOperation},
+		{in: "max(\"a\", 1)", expectedErr: ErrArithmeticOperation},
+		{in: "floor(2)", expectedValue: int64Value(2)},
+		{in: "floor(2.7)", expectedValue: float64Value(2)},
+		{in: "floor(-2.5)", expectedValue: float64Value(-3)},
+		{in: "floor(2.7)", numericType: NumericTypeInt, expectedValue: int64Value(2)},
+		{in: "floor(2.7)", numericType: NumericTypeFloat, expectedValue: float64Value(2)},
+		{in: "floor(2.7)", numericType: NumericTypeComplex, expectedValue: complex128Value(2)},
+		{in: "floor(2.7i)", expectedErr: ErrArithmeticOperation},
+		{in: "ceil(2.1)", expectedValue: float64Value(3)},
+		{in: "trunc(-2.7)", expectedValue: float64Value(-2)},
+		{in: "round(2.5)", expectedValue: float64Value(3)},
+		{in: "round(-2.5)", expectedValue: float64Value(-3)},
+		{in: "round(3.14159, 2)", expectedValue: float64Value(3.14)},
+		{in: "round(1234.5, -2)", expectedValue: float64Value(1200)},
+		{in: "round(1250, -2)", expectedValue: int64Value(1300)},
+		{in: "round(-1250, -2)", expectedValue: int64Value(-1300)},
+		{in: "round(1250, 2)", expectedValue: int64Value(1250)},
+		{in: "round(1250, -19)", expectedValue: int64Value(0)},
+		{in: "round(9223372036854775807, -1)", expectedErr: ErrIntegerOverflow},
+		{in: "round(-9223372036854775808, -1)", expectedErr: ErrIntegerOverflow},
+		{in: "round(9223372036854775807, -2)", expectedValue: int64Value(9223372036854775800)},
+		{in: "round(-9223372036854775808, -2)", expectedValue: int64Value(-9223372036854775800)},
+		{in: "round(9223372036854775807, -18)", expectedValue: int64Value(9000000000000000000)},
+		{in: "round(9223372036854775807, -19)", expectedErr: ErrIntegerOverflow},
+		{in: "round(-9223372036854775808, -19)", expectedErr: ErrIntegerOverflow},
+		{in: "round(-9223372036854775808, -20)", expectedValue: int64Value(0)},
+		{in: "round(4999999999999999999, -19)", expectedValue: int64Value(0)},
+		{in: "round(1.5, -400)", expectedValue: float64Value(0)},
+		{in: "round(1.5, 400)", expectedValue: float64Value(1.5)},
+		{in: "round(2.5, 0)", numericType: NumericTypeComplex, expectedValue: complex128Value(3)},
+		{in: "round(2.5i)", expectedErr: ErrArithmeticOperation},
+		{in: "round(2.5, 1, 2)", expectedErr: ErrFunctionCall},
+		{in: "round(2.5, 1.5)", expectedErr: ErrFunctionCall},
+		{in: "sqrt(16)", expectedValue: float64Value(4)},
+		{in: "sqrt(-1)", expectedErr: ErrArithmeticOperation},
+		{in: "sqrt(-1)", numericType: NumericTypeComplex, expectedValue: complex128Value(1i)},
+		{in: "sqrt(-1+0i)", expectedValue: complex128Value(1i)},
+		{in: "cbrt(-27)", expectedValue: float64Value(-3)},
+		{in: "pow(2, 10)", expectedValue: int64Value(1024)},
+		{in: "pow(3, 0)", expectedValue: int64Value(1)},
+		{in: "pow(-2, 63)", numericType: NumericTypeInt, expectedValue: int64Value(math.MinInt64)},
+		{in: "pow(2, 64)", numericType: NumericTypeInt, expectedValue: float64Value(math.Pow(2, 64))},
+		{in: "pow(3, 50)", numericType: NumericTypeInt, expectedValue: float64Value(math.Pow(3, 50))},
+		{in: "pow(-3, 41)", numericType: NumericTypeInt, expectedValue: float64Value(math.Pow(-3, 41))},
+		{in: "pow(1, 9223372036854775807)", numericType: NumericTypeInt, expectedValue: int64Value(1)},
+		{in: "pow(2, -1)", expectedValue: float64Value(0.5)},
+		{in: "pow(4, 0.5)", expectedValue: float64Value(2)},
+		{in: "pow(-8, 1.0/3)", expectedErr: ErrArithmeticOperation},
+		{in: "pow(1i, 2)", expectedValue: complex128Value(cmplx.Pow(1i, 2))},
+		{in: "exp(0)", expectedValue: float64Value(1)},
+		{in: "log(e)", expectedValue: float64Value(1)},
+		{in: "log(-1)", expectedErr: ErrArithmeticOperation},
+		{in: "log(0)", expectedValue: float64Value(math.Inf(-1))},
+		{in: "log2(8)", expectedValue: float64Value(3)},
+		{in: "log2(8+0i)", expectedValue: complex128Value(cmplx.Log(8) / math.Ln2)},
+		{in: "log10(1000)", expectedValue: float64Value(3)},
+		{in: "hypot(3, 4)", expectedValue: float64Value(5)},
+		{in: "sin(0)", expectedValue: float64Value(0)},
+		{in: "cos(pi)", expectedValue: float64Value(-1)},
+		{in: "tan(0)", expectedValue: float64Value(0)},
+		{in: "asin(1)", expectedValue: float64Value(math.Pi / 2)},
+		{in: "asin(2)", expectedErr: ErrArithmeticOperation},
+		{in: "acos(1)", expectedValue: float64Value(0)},
+		{in: "atan(0)", expectedValue: float64Value(0)},
+		{in: "pi * 2", expectedValue: float64Value(math.Pi * 2)},
+		{in: "e", expectedValue: float64Value(math.E)},
+	}
+
+	for i, tc := range tt {
+		tc := tc
+		t.Run(fmt.Sprintf("[%d] %s", i, tc.in), func(t *testing.T) {
+			e, err := parser.ParseExpr(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := NewVisitor(WithNumericType(tc.numericType), WithMath())
+			ast.Walk(v, e)
+
+			if err := v.Err(); !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected err: %v, got: %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr != nil {
+				return
+			}
+			if v.Kind() != tc.expectedValue.Kind() {
+				t.Fatalf("expected kind: %s, got: %s", tc.expectedValue.Kind(), v.Kind())
+			}
+			if val := v.ValueAny(); val != tc.expectedValue.Any() {
+				t.Fatalf("expected val: %v (%T), got: %v (%T)", tc.expectedValue.Any(), tc.expectedValue.Any(), val, val)
+			}
+		})
+	}
+
+	t.Run("optional arguments", func(t *testing.T) {
+		for _, in := range []string{"round(2.5)", "round(2.5, 1)"} {
+			if _, err := Compile(in, WithMath()); err != nil {
+				t.Fatalf("%s: %v", in, err)
+			}
+		}
+
+		_, err := Compile("round(2.5, 1, 2)", WithMath())
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) || !errors.Is(err, ErrFunctionCall) {
+			t.Fatalf("expected SyntaxError wrapping %v, got: %v", ErrFunctionCall, err)
+		}
+		if syntaxErr.Pos != 15 { // the extra argument
+			t.Fatalf("expected pos: 15, got: %d", syntaxErr.Pos)
+		}
+	})
+
+	t.Run("overridden", func(t *testing.T) {
+		e, err := parser.ParseExpr("pi + abs(1)")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		v := NewVisitor(WithMath(), WithEnv(map[string]interface{}{"pi": 3}), WithFunctions(map[string]Func{
+			"abs": {Args: []Kind{KindAny}, Return: KindInt, Fn: func(args ...interface{}) (interface{}, error) { return 10, nil }},
+		}))
+		ast.Walk(v, e)
+		if err := v.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if val := v.ValueAny(); val != float64(13) {
+			t.Fatalf("expected val: %v, got: %v", float64(13), val)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"go/ast"
+	"go/token"
+
+	"github.com/muktihari/expr/internal/conv"
+)
+
+// WithNilPropagation makes arithmetic and bitwise operation that has nil operand results nil instead of returning
+// an error, e.g. "nil + 1" -> nil and "-nil" -> nil. Comparison and logical operation are not affected.
+func WithNilPropagation(v bool) Option {
+	return func(o *options) { o.nilPropagation = v }
+}
+
+// propagateNil sets v's value to nil if one of the operands is nil and nil propagation is enabled.
+func propagateNil(v *Visitor, operands ...value) bool {
+	if !v.options.nilPropagation {
+		return false
+	}
+	for i := range operands {
+		if operands[i].Kind() == KindNil {
+			v.value = nilValue()
+			return true
+		}
+	}
+	return false
+}
+
+// compareNil compares values where at least one of them is nil, nil is only equal to nil.
+func compareNil(v *Visitor, vx, vy *Visitor, binaryExpr *ast.BinaryExpr) {
+	equal := vx.value.Kind() == KindNil && vy.value.Kind() == KindNil
+	switch binaryExpr.Op {
+	case token.EQL:
+		v.value = boolValue(equal)
+	case token.NEQ:
+		v.value = boolValue(!equal)
+	default:
+		v.value = value{}
+		v.err = &SyntaxError{
+			Msg: "operator \"" + binaryExpr.Op.String() + "\" is not supported for comparing nil: \"" +
+				conv.FormatExpr(binaryExpr) + "\"",
+			Pos: int(binaryExpr.OpPos),
+			Err: ErrUnsupportedOperator,
+		}
+	}
+}
+
+// checkCoalesce infers coalesce's result for Check, it is KindAny if the arguments that are not nil have
+// different Kind.
+func checkCoalesce(c *checker, callExpr *ast.CallExpr, args []checked) checked {
+	nonNil := make([]checked, 0, len(args))
+	for i := range args {
+		if args[i].kind != KindNil {
+			nonNil = append(nonNil, args[i])
+		}
+	}
+	if len(nonNil) == 0 {
+		return c.variable(KindNil)
+	}
+	return c.variable(commonKind(nonNil...))
+}
+
+// builtinCoalesce returns the first argument that is not nil: coalesce(order.coupon.code, "none"). Arguments are
+// evaluated lazily from left to right with missing field and index out of range treated as nil, it returns nil
+// if all arguments are nil.
+func builtinCoalesce(v *Visitor, callExpr *ast.CallExpr) {
+	o := v.options
+	o.missingAsNil = true
+
+	va := pool.Get().(*Visitor)
+	defer pool.Put(va)
+
+	for _, arg := range callExpr.Args {
+		va.reset(o)
+		va.Visit(arg)
+		if va.err != nil {
+			v.err = va.err
+			return
+		}
+		if va.value.Kind() != KindNil {
+			v.value = va.value
+			return
+		}
+	}
+	v.value = nilValue()
+}
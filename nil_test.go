@@ -0,0 +1,111 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"testing"
+)
+
+func TestNil(t *testing.T) {
+	type Coupon struct{ Code string }
+	type Order struct {
+		Coupon *Coupon
+		Labels map[string]string
+	}
+
+	env := map[string]interface{}{
+		"coupon": nil,
+		"order":  &Order{Labels: map[string]string{"env": "prod"}},
+		"items":  []int{1, 2},
+		"qty":    2,
+	}
+
+	tt := []struct {
+		in           string
+		options      []Option
+		expectedKind Kind
+		expectedStr  string
+		expectedErr  error
+		expectedPos  int
+	}{
+		{in: `nil`, expectedKind: KindNil, expectedStr: "<nil>"},
+		{in: `coupon == nil`, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `nil != coupon`, expectedKind: KindBoolean, expectedStr: "false"},
+		{in: `qty == nil`, expectedKind: KindBoolean, expectedStr: "false"},
+		{in: `"nil" != nil`, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `order == nil`, expectedKind: KindBoolean, expectedStr: "false"},
+		{in: `order.Coupon == nil`, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `coupon == nil`, options: []Option{WithStrictIdents(true)}, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `in(nil, []any{1, nil})`, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `coupon < 1`, expectedErr: ErrUnsupportedOperator, expectedPos: 8},
+		{in: `coupon + 1`, expectedErr: ErrArithmeticOperation},
+		{in: `coupon | 1`, expectedErr: ErrBitwiseOperation},
+		{in: `-coupon`, expectedErr: ErrUnaryOperation, expectedPos: 2},
+		{in: `!coupon`, expectedErr: ErrUnaryOperation},
+		{in: `coupon + 1`, options: []Option{WithNilPropagation(true)}, expectedKind: KindNil, expectedStr: "<nil>"},
+		{in: `qty * 2 - order.Labels["discount"]`, options: []Option{WithNilPropagation(true), WithMissingAsNil(true)}, expectedKind: KindNil, expectedStr: "<nil>"},
+		{in: `coupon | 1`, options: []Option{WithNilPropagation(true)}, expectedKind: KindNil, expectedStr: "<nil>"},
+		{in: `-coupon`, options: []Option{WithNilPropagation(true)}, expectedKind: KindNil, expectedStr: "<nil>"},
+		{in: `(coupon + 1) == nil`, options: []Option{WithNilPropagation(true)}, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `!coupon`, options: []Option{WithNilPropagation(true)}, expectedErr: ErrUnaryOperation},
+		{in: `coupon && true`, options: []Option{WithNilPropagation(true)}, expectedErr: ErrLogicalOperation},
+		{in: `coupon + 1 > 2`, options: []Option{WithNilPropagation(true)}, expectedErr: ErrUnsupportedOperator},
+		{in: `coalesce(coupon, "none")`, expectedKind: KindString, expectedStr: "none"},
+		{in: `coalesce(order.Coupon.Code, order.Labels["code"], "none")`, expectedKind: KindString, expectedStr: "none"},
+		{in: `coalesce(order.Labels["env"], "none")`, expectedKind: KindString, expectedStr: "prod"},
+		{in: `coalesce(items[2], items[1])`, expectedKind: KindInt, expectedStr: "2"},
+		{in: `coalesce(coupon, nil)`, expectedKind: KindNil, expectedStr: "<nil>"},
+		{in: `coalesce(qty, 1 + true)`, expectedKind: KindInt, expectedStr: "2"}, // lazy
+		{in: `coalesce(coupon, 1 + true)`, expectedErr: ErrArithmeticOperation},
+		{in: `coalesce(order.Labels["discount"], 0) + 1`, expectedKind: KindFloat, expectedStr: "1"},
+		{in: `order.Labels["discount"]`, expectedErr: ErrMissingField}, // coalesce does not change other expressions
+		{in: `coalesce()`, expectedErr: ErrFunctionCall, expectedPos: 10},
+	}
+
+	for i, tc := range tt {
+		tc := tc
+		t.Run(fmt.Sprintf("[%d] %s", i, tc.in), func(t *testing.T) {
+			e, err := parser.ParseExpr(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := NewVisitor(append([]Option{WithEnv(env)}, tc.options...)...)
+			ast.Walk(v, e)
+
+			if err := v.Err(); !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected err: %v, got: %v", tc.expectedErr, err)
+			}
+			if tc.expectedPos != 0 {
+				var syntaxErr *SyntaxError
+				if !errors.As(v.Err(), &syntaxErr) || syntaxErr.Pos != tc.expectedPos {
+					t.Fatalf("expected pos: %d, got: %v", tc.expectedPos, v.Err())
+				}
+			}
+			if tc.expectedErr != nil {
+				return
+			}
+			if v.Kind() != tc.expectedKind {
+				t.Fatalf("expected kind: %s, got: %s", tc.expectedKind, v.Kind())
+			}
+			if str := fmt.Sprint(v.ValueAny()); str != tc.expectedStr {
+				t.Fatalf("expected val: %s, got: %s", tc.expectedStr, str)
+			}
+		})
+	}
+}
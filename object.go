@@ -0,0 +1,322 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"fmt"
+	"go/ast"
+	"reflect"
+	"strconv"
+
+	"github.com/muktihari/expr/internal/conv"
+)
+
+// object holds Go's composite value: map, slice, array, struct or pointer to them.
+type object struct{ x interface{} }
+
+// objectValue creates object value.
+func objectValue(x interface{}) value { return value{any: object{x: x}} }
+
+// nilValue creates nil value.
+func nilValue() value { return value{any: KindNil} }
+
+// elementValue creates value from an element of an object, nil element such as JSON's null is a nil value.
+func elementValue(x interface{}) (value, bool) {
+	if isNil(x) {
+		return nilValue(), true
+	}
+	return valueOf(x)
+}
+
+func isNil(x interface{}) bool {
+	if x == nil {
+		return true
+	}
+	rv := reflect.ValueOf(x)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// WithMissingAsNil treats missing field, missing map key and index out of range as nil instead of returning an error,
+// e.g. "order.coupon.code" is nil if order has no coupon.
+func WithMissingAsNil(v bool) Option {
+	return func(o *options) { o.missingAsNil = v }
+}
+
+// visitSelector handles field access of struct or map with string key: order.customer.tier.
+func (v *Visitor) visitSelector(selectorExpr *ast.SelectorExpr) ast.Visitor {
+	vx := pool.Get().(*Visitor)
+	defer pool.Put(vx)
+	vx.reset(v.options)
+
+	vx.Visit(selectorExpr.X)
+	if vx.err != nil {
+		v.err = vx.err
+		return nil
+	}
+
+	name := selectorExpr.Sel.Name
+	switch vx.value.Kind() {
+	case KindObject:
+		val, found, ok := selectField(vx.value.Object(), name)
+		if !ok {
+			v.err = &SyntaxError{
+				Msg: fmt.Sprintf("could not select %q: result of %q is %T which has no fields",
+					name, conv.FormatExpr(selectorExpr.X), vx.value.Object()),
+				Pos: int(selectorExpr.Sel.NamePos),
+				Err: ErrIndexOperation,
+			}
+			return nil
+		}
+		if !found {
+			v.missing(&SyntaxError{
+				Msg: fmt.Sprintf("field %q is not found in %q", name, conv.FormatExpr(selectorExpr.X)),
+				Pos: int(selectorExpr.Sel.NamePos),
+				Err: ErrMissingField,
+			})
+			return nil
+		}
+		v.setElement(val, selectorExpr)
+	case KindNil:
+		v.missing(newNilSelectionError(name, selectorExpr.X, int(selectorExpr.Sel.NamePos)))
+	default:
+		v.err = newNoFieldsError(selectorExpr, vx.value)
+	}
+	return nil
+}
+
+// newNoFieldsError creates error for selecting a field of val which is neither an object nor nil.
+func newNoFieldsError(selectorExpr *ast.SelectorExpr, val value) error {
+	return &SyntaxError{
+		Msg: "could not select \"" + selectorExpr.Sel.Name + "\": result of \"" + conv.FormatExpr(selectorExpr.X) + "\" is \"" +
+			fmt.Sprintf("%v", val.Any()) + "\" which has no fields",
+		Pos: int(selectorExpr.Sel.NamePos),
+		Err: ErrIndexOperation,
+	}
+}
+
+// indexObject handles index access of slice, array or map: items[0], labels["env"].
+func (v *Visitor) indexObject(vx *Visitor, indexExpr *ast.IndexExpr) {
+	vi := pool.Get().(*Visitor)
+	defer pool.Put(vi)
+	vi.reset(v.options)
+
+	vi.Visit(indexExpr.Index)
+	if vi.err != nil {
+		v.err = vi.err
+		return
+	}
+
+	x := vx.value.Object()
+	if vi.value.Kind() == KindString { // map key or struct's field
+		val, found, ok := selectField(x, vi.value.String())
+		switch {
+		case !ok:
+			v.err = newInvalidIndexError(vi, indexExpr.Index, fmt.Sprintf("%T", x))
+		case !found:
+			v.missing(&SyntaxError{
+				Msg: fmt.Sprintf("key %q is not found in %q", vi.value.String(), conv.FormatExpr(indexExpr.X)),
+				Pos: vi.pos,
+				Err: ErrMissingField,
+			})
+		default:
+			v.setElement(val, indexExpr)
+		}
+		return
+	}
+
+	if list, ok := x.([]interface{}); ok { // fast path
+		i, ok := v.toIndex(vi, indexExpr.Index)
+		if !ok {
+			return
+		}
+		if i < 0 || i >= len(list) {
+			v.missing(newIndexOutOfRangeError("index "+strconv.Itoa(i)+" is out of range with length "+strconv.Itoa(len(list)), indexExpr.Index))
+			return
+		}
+		v.setElement(list[i], indexExpr)
+		return
+	}
+
+	rv := indirect(reflect.ValueOf(x))
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		i, ok := v.toIndex(vi, indexExpr.Index)
+		if !ok {
+			return
+		}
+		if i < 0 || i >= rv.Len() {
+			v.missing(newIndexOutOfRangeError("index "+strconv.Itoa(i)+" is out of range with length "+strconv.Itoa(rv.Len()), indexExpr.Index))
+			return
+		}
+		v.setElement(rv.Index(i).Interface(), indexExpr)
+	case reflect.Map:
+		key, ok := mapKey(vi.value, rv.Type().Key())
+		if !ok {
+			v.err = newInvalidIndexError(vi, indexExpr.Index, rv.Type().String())
+			return
+		}
+		var mv reflect.Value
+		if key.IsValid() { // invalid key is out of the key type's range, so it is not found.
+			mv = rv.MapIndex(key)
+		}
+		if !mv.IsValid() {
+			v.missing(&SyntaxError{
+				Msg: fmt.Sprintf("key %v is not found in %q", vi.value.Any(), conv.FormatExpr(indexExpr.X)),
+				Pos: vi.pos,
+				Err: ErrMissingField,
+			})
+			return
+		}
+		v.setElement(mv.Interface(), indexExpr)
+	default:
+		v.err = newNonIndexableError(vx, indexExpr.X)
+	}
+}
+
+// setElement sets v's value with the element x that is resolved from e.
+func (v *Visitor) setElement(x interface{}, e ast.Expr) {
+	var ok bool
+	if v.value, ok = elementValue(x); !ok {
+		v.err = &SyntaxError{
+			Msg: fmt.Sprintf("result of %q has unsupported type %T", conv.FormatExpr(e), x),
+			Pos: int(e.Pos()),
+			Err: ErrUnsupportedVariableType,
+		}
+	}
+}
+
+// missing sets v's value as nil if missingAsNil is true, otherwise, it sets v's err with err.
+func (v *Visitor) missing(err error) {
+	if v.options.missingAsNil {
+		v.value = nilValue()
+		return
+	}
+	v.err = err
+}
+
+func newNilSelectionError(name string, x ast.Expr, pos int) error {
+	return &SyntaxError{
+		Msg: "could not select \"" + name + "\": result of \"" + conv.FormatExpr(x) + "\" is nil",
+		Pos: pos,
+		Err: ErrMissingField,
+	}
+}
+
+func newInvalidIndexError(vi *Visitor, e ast.Expr, typ string) error {
+	return &SyntaxError{
+		Msg: fmt.Sprintf("invalid index: result of %q is \"%v\" which is not a valid index of %s",
+			conv.FormatExpr(e), vi.value.Any(), typ),
+		Pos: vi.pos,
+		Err: ErrIndexOperation,
+	}
+}
+
+// selectField returns the value of x's field or x's map key named name. It returns ok false if x is neither
+// struct nor map with string key (or pointer to them), unexported field is treated as not found.
+func selectField(x interface{}, name string) (val interface{}, found, ok bool) {
+	if m, isMap := x.(map[string]interface{}); isMap { // fast path
+		val, found = m[name]
+		return val, found, true
+	}
+
+	rv := indirect(reflect.ValueOf(x))
+	switch rv.Kind() {
+	case reflect.Struct:
+		sf, exists := rv.Type().FieldByName(name)
+		if !exists || sf.PkgPath != "" {
+			return nil, false, true
+		}
+		for _, i := range sf.Index { // walk manually since embedded struct's pointer might be nil.
+			if rv = indirect(rv); rv.Kind() != reflect.Struct {
+				return nil, false, true
+			}
+			rv = rv.Field(i)
+		}
+		if !rv.CanInterface() { // promoted from unexported embedded struct
+			return nil, false, true
+		}
+		return rv.Interface(), true, true
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, false, false
+		}
+		mv := rv.MapIndex(reflect.ValueOf(name).Convert(rv.Type().Key()))
+		if !mv.IsValid() {
+			return nil, false, true
+		}
+		return mv.Interface(), true, true
+	}
+	return nil, false, false
+}
+
+// mapKey converts val into a map's key of type typ, only if val and typ are both string, bool or numbers.
+// The key is invalid if val is an integer out of typ's range, e.g. 300 for int8 or -1 for uint, it is never in the map.
+func mapKey(val value, typ reflect.Type) (reflect.Value, bool) {
+	var x interface{}
+	switch typ.Kind() {
+	case reflect.String:
+		if val.Kind() != KindString {
+			return reflect.Value{}, false
+		}
+		x = val.String()
+	case reflect.Bool:
+		if val.Kind() != KindBoolean {
+			return reflect.Value{}, false
+		}
+		x = val.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := convertArg(val, KindInt, NumericTypeAuto)
+		if !ok {
+			return reflect.Value{}, isInteger(val) // out of int64's range
+		}
+		if reflect.Zero(typ).OverflowInt(i.(int64)) {
+			return reflect.Value{}, true
+		}
+		x = i
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, ok := convertArg(val, KindUint, NumericTypeAuto)
+		if !ok {
+			_, isInt := convertArg(val, KindInt, NumericTypeAuto) // negative
+			return reflect.Value{}, isInt || isInteger(val)
+		}
+		if reflect.Zero(typ).OverflowUint(u.(uint64)) {
+			return reflect.Value{}, true
+		}
+		x = u
+	case reflect.Float32, reflect.Float64:
+		f, ok := convertArg(val, KindFloat, NumericTypeAuto)
+		if !ok {
+			return reflect.Value{}, false
+		}
+		x = f
+	default:
+		return reflect.Value{}, false
+	}
+	return reflect.ValueOf(x).Convert(typ), true
+}
+
+// indirect dereferences rv's pointer or interface until it's neither of them, nil pointer returns invalid value.
+func indirect(rv reflect.Value) reflect.Value {
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return reflect.Value{}
+		}
+		rv = rv.Elem()
+	}
+	return rv
+}
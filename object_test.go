@@ -0,0 +1,170 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"testing"
+)
+
+func TestSelectorAndIndex(t *testing.T) {
+	type Customer struct {
+		Name string
+		Tier string
+	}
+	type Base struct{ ID int }
+	type item struct {
+		Price float64
+		Tags  []string
+	}
+	type Order struct {
+		*Base
+		Customer *Customer
+		Items    []item
+		Labels   map[string]string
+		Scores   map[int]float64
+		Coupon   *Customer
+		secret   string
+	}
+
+	var payload map[string]interface{}
+	err := json.Unmarshal([]byte(`{
+		"order": {"customer": {"tier": "gold"}, "items": [{"price": 12.5}, {"price": 8}], "coupon": null},
+		"labels": {"env": "prod"}
+	}`), &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]interface{}{
+		"payload": payload,
+		"order": &Order{
+			Base:     &Base{ID: 7},
+			Customer: &Customer{Name: "gopher", Tier: "gold"},
+			Items:    []item{{Price: 12.5, Tags: []string{"a", "b"}}, {Price: 8}},
+			Labels:   map[string]string{"env": "prod"},
+			Scores:   map[int]float64{1: 0.5},
+			secret:   "s3cr3t",
+		},
+		"noBase": Order{},
+		"matrix": [2][2]int{{1, 2}, {3, 4}},
+		"small":  map[int8]string{44: "x", -1: "y"},
+		"bytes":  map[uint8]string{44: "x", 255: "y"},
+	}
+
+	tt := []struct {
+		in           string
+		options      []Option
+		expectedKind Kind
+		expectedStr  string
+		expectedErr  error
+		expectedPos  int
+	}{
+		{in: `payload.order.customer.tier == "gold"`, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `payload.order.items[0].price > 10`, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `payload.order.items[1].price + payload.order.items[0].price`, expectedKind: KindFloat, expectedStr: "20.5"},
+		{in: `payload.labels["env"] == "prod"`, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `payload["labels"].env`, expectedKind: KindString, expectedStr: "prod"},
+		{in: `payload.order.coupon`, expectedKind: KindNil, expectedStr: "<nil>"},
+		{in: `order.Customer.Tier == "gold"`, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `order.Items[0].Price > 10`, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `order.Items[0].Tags[1]`, expectedKind: KindString, expectedStr: "b"},
+		{in: `order.Labels["env"] == "prod"`, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `order.Labels.env`, expectedKind: KindString, expectedStr: "prod"},
+		{in: `order.Scores[1]`, expectedKind: KindFloat, expectedStr: "0.5"},
+		{in: `order.Scores[1.0]`, expectedKind: KindFloat, expectedStr: "0.5"},
+		{in: `order.ID`, expectedKind: KindInt, expectedStr: "7"},
+		{in: `order["Customer"]["Name"]`, expectedKind: KindString, expectedStr: "gopher"},
+		{in: `matrix[1][0]`, expectedKind: KindInt, expectedStr: "3"},
+		{in: `order.Items[0].Tags[1][0]`, expectedKind: KindUint, expectedStr: "98"},
+		{in: `small[44]`, expectedKind: KindString, expectedStr: "x"},
+		{in: `small[-1]`, expectedKind: KindString, expectedStr: "y"},
+		{in: `bytes[255]`, expectedKind: KindString, expectedStr: "y"},
+		// missing
+		{in: `payload.order.customer.name`, expectedErr: ErrMissingField, expectedPos: 24},
+		{in: `payload.labels["team"]`, expectedErr: ErrMissingField, expectedPos: 16},
+		{in: `payload.order.items[2].price`, expectedErr: ErrIndexOutOfRange, expectedPos: 21},
+		{in: `payload.order.coupon.code`, expectedErr: ErrMissingField, expectedPos: 22},
+		{in: `order.Items[-1]`, expectedErr: ErrIndexOutOfRange, expectedPos: 13},
+		{in: `order.Labels["team"]`, expectedErr: ErrMissingField, expectedPos: 14},
+		{in: `order.Scores[2]`, expectedErr: ErrMissingField, expectedPos: 14},
+		{in: `order.secret`, expectedErr: ErrMissingField, expectedPos: 7},
+		{in: `order.Coupon.Tier`, expectedErr: ErrMissingField, expectedPos: 14},
+		{in: `noBase.ID`, expectedErr: ErrMissingField, expectedPos: 8},
+		{in: `payload.order.coupon[0]`, expectedErr: ErrMissingField, expectedPos: 21},
+		{in: `small[300]`, expectedErr: ErrMissingField, expectedPos: 7}, // not truncated into 44
+		{in: `small[-129]`, expectedErr: ErrMissingField, expectedPos: 7},
+		{in: `small[0xFFFFFFFFFFFFFFFF]`, expectedErr: ErrMissingField, expectedPos: 7},
+		{in: `bytes[300]`, expectedErr: ErrMissingField, expectedPos: 7},
+		{in: `bytes[-1]`, expectedErr: ErrMissingField, expectedPos: 7}, // not wrapped into 255
+		{in: `bytes[-212]`, expectedErr: ErrMissingField, expectedPos: 7},
+		// missing as nil
+		{in: `payload.order.customer.name`, options: []Option{WithMissingAsNil(true)}, expectedKind: KindNil, expectedStr: "<nil>"},
+		{in: `payload.order.items[2].price`, options: []Option{WithMissingAsNil(true)}, expectedKind: KindNil, expectedStr: "<nil>"},
+		{in: `payload.order.coupon.code`, options: []Option{WithMissingAsNil(true)}, expectedKind: KindNil, expectedStr: "<nil>"},
+		{in: `order.Coupon["Tier"]`, options: []Option{WithMissingAsNil(true)}, expectedKind: KindNil, expectedStr: "<nil>"},
+		{in: `order.Labels["team"]`, options: []Option{WithMissingAsNil(true)}, expectedKind: KindNil, expectedStr: "<nil>"},
+		{in: `small[300]`, options: []Option{WithMissingAsNil(true)}, expectedKind: KindNil, expectedStr: "<nil>"},
+		// invalid
+		{in: `order.Customer.Tier.x`, expectedErr: ErrIndexOperation, expectedPos: 21},
+		{in: `order.Items.Price`, expectedErr: ErrIndexOperation, expectedPos: 13},
+		{in: `order.Items.Price`, options: []Option{WithMissingAsNil(true)}, expectedErr: ErrIndexOperation, expectedPos: 13},
+		{in: `order.Items["0"]`, expectedErr: ErrIndexOperation, expectedPos: 13},
+		{in: `order.Items[0.5]`, expectedErr: ErrIndexOperation, expectedPos: 13},
+		{in: `order.Scores["1"]`, expectedErr: ErrIndexOperation, expectedPos: 14},
+		{in: `bytes[1.5]`, expectedErr: ErrIndexOperation, expectedPos: 7},
+		{in: `order.Labels[1]`, expectedErr: ErrIndexOperation, expectedPos: 14},
+		{in: `order[0]`, expectedErr: ErrIndexOperation, expectedPos: 1},
+		{in: `order.Items[1 + true]`, expectedErr: ErrArithmeticOperation},
+		{in: `(1 + true).x`, expectedErr: ErrArithmeticOperation},
+		{in: `order.Items + 1`, expectedErr: ErrArithmeticOperation},
+		{in: `order.Items == order.Items`, expectedErr: ErrComparisonOperation},
+	}
+
+	for i, tc := range tt {
+		tc := tc
+		t.Run(fmt.Sprintf("[%d] %s", i, tc.in), func(t *testing.T) {
+			e, err := parser.ParseExpr(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := NewVisitor(append([]Option{WithEnv(env)}, tc.options...)...)
+			ast.Walk(v, e)
+
+			if err := v.Err(); !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected err: %v, got: %v", tc.expectedErr, err)
+			}
+			if tc.expectedPos != 0 {
+				var syntaxErr *SyntaxError
+				if !errors.As(v.Err(), &syntaxErr) || syntaxErr.Pos != tc.expectedPos {
+					t.Fatalf("expected pos: %d, got: %v", tc.expectedPos, v.Err())
+				}
+			}
+			if tc.expectedErr != nil {
+				return
+			}
+			if v.Kind() != tc.expectedKind {
+				t.Fatalf("expected kind: %s, got: %s", tc.expectedKind, v.Kind())
+			}
+			if str := fmt.Sprint(v.ValueAny()); str != tc.expectedStr {
+				t.Fatalf("expected val: %s, got: %s", tc.expectedStr, str)
+			}
+		})
+	}
+}
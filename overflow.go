@@ -0,0 +1,188 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"go/token"
+	"math"
+	"math/bits"
+	"strconv"
+)
+
+// OverflowPolicy decides what happens when an integer operation [+, -, *, /, unary -, <<] overflows int64 or uint64.
+// It applies to integer operations only, NumericTypeAuto calculates arithmetic as float64 but not bitwise.
+type OverflowPolicy byte
+
+const (
+	OverflowWrap     OverflowPolicy = iota // wrap around silently like Go (default): MaxInt64 + 1 = MinInt64, MaxUint64 + 1 = 0
+	OverflowError                          // return SyntaxError wrapping ErrIntegerOverflow
+	OverflowSaturate                       // clamp into the nearest bound: MaxInt64 + 1 = MaxInt64, uint64(0) - 1 = 0
+)
+
+var overflowPolicies = [...]string{
+	OverflowWrap:     "OverflowWrap",
+	OverflowError:    "OverflowError",
+	OverflowSaturate: "OverflowSaturate",
+}
+
+func (p OverflowPolicy) String() string {
+	if int(p) < len(overflowPolicies) {
+		return overflowPolicies[p]
+	}
+	return "OverflowPolicy(" + strconv.Itoa(int(p)) + ")"
+}
+
+// WithOverflowPolicy sets what to do when an integer operation overflows int64, the default is OverflowWrap.
+func WithOverflowPolicy(p OverflowPolicy) Option {
+	return func(o *options) { o.overflowPolicy = p }
+}
+
+// WithOverflowCheck is a shorthand of WithOverflowPolicy(OverflowError) if check is true,
+// otherwise WithOverflowPolicy(OverflowWrap).
+func WithOverflowCheck(check bool) Option {
+	if check {
+		return WithOverflowPolicy(OverflowError)
+	}
+	return WithOverflowPolicy(OverflowWrap)
+}
+
+// setInt64 sets v's value with r, the wrapped result of an operation. If the operation overflows,
+// it is handled based on v's overflow policy, positive is the sign of the actual result.
+func setInt64(v *Visitor, r int64, overflow, positive bool, op token.Token, opPos token.Pos) {
+	if !overflow {
+		v.value = int64Value(r)
+		return
+	}
+
+	switch v.options.overflowPolicy {
+	case OverflowError:
+		v.value = value{}
+		v.err = &SyntaxError{
+			Msg: "operator \"" + op.String() + "\" overflows int64",
+			Pos: int(opPos),
+			Err: ErrIntegerOverflow,
+		}
+	case OverflowSaturate:
+		if positive {
+			v.value = int64Value(math.MaxInt64)
+			return
+		}
+		v.value = int64Value(math.MinInt64)
+	default:
+		v.value = int64Value(r)
+	}
+}
+
+func addInt64(x, y int64) (r int64, overflow bool) {
+	r = x + y
+	return r, (x^r)&(y^r) < 0 // x and y have the same sign but r has not
+}
+
+func subInt64(x, y int64) (r int64, overflow bool) {
+	r = x - y
+	return r, (x^y)&(x^r) < 0 // x and y have different sign and r's sign is not x's
+}
+
+func mulInt64(x, y int64) (r int64, overflow bool) {
+	if x == 0 || y == 0 {
+		return 0, false
+	}
+	r = x * y
+	return r, r/y != x || (x == -1 && y == math.MinInt64) || (y == -1 && x == math.MinInt64)
+}
+
+// shlInt64 shifts x by y bits to the left, y must not be negative.
+func shlInt64(x, y int64) (r int64, overflow bool) {
+	if y >= 64 {
+		return 0, x != 0
+	}
+	r = x << uint64(y)
+	return r, r>>uint64(y) != x
+}
+
+// setUint64 is the uint64 version of setInt64.
+func setUint64(v *Visitor, r uint64, overflow, positive bool, op token.Token, opPos token.Pos) {
+	if !overflow {
+		v.value = uint64Value(r)
+		return
+	}
+
+	switch v.options.overflowPolicy {
+	case OverflowError:
+		v.value = value{}
+		v.err = &SyntaxError{
+			Msg: "operator \"" + op.String() + "\" overflows uint64",
+			Pos: int(opPos),
+			Err: ErrIntegerOverflow,
+		}
+	case OverflowSaturate:
+		if positive {
+			v.value = uint64Value(math.MaxUint64)
+			return
+		}
+		v.value = uint64Value(0)
+	default:
+		v.value = uint64Value(r)
+	}
+}
+
+// setMixedInt sets v's value with the exact result of an operation mixing int64 and uint64 whose sign is neg and
+// magnitude is mag: uint64 if it is not negative, otherwise int64. If the result is representable as neither int64
+// nor uint64, mag holds its low 64 bits and it wraps around into uint64 like Go's uint64 operation by default, e.g.
+// 0xFFFFFFFFFFFFFFFF + 1 = 0, it is an error wrapping ErrIntegerOverflow using OverflowError, or it is clamped into
+// MinInt64 or MaxUint64 using OverflowSaturate.
+func setMixedInt(v *Visitor, neg bool, mag uint64, overflow bool, op token.Token, opPos token.Pos) {
+	if neg && mag > 1<<63 {
+		overflow = true
+	}
+	switch {
+	case !overflow && neg:
+		v.value = int64Value(int64(-mag)) // -(1 << 63) is MinInt64.
+	case !overflow:
+		v.value = uint64Value(mag)
+	case v.options.overflowPolicy == OverflowSaturate && neg:
+		v.value = int64Value(math.MinInt64)
+	case v.options.overflowPolicy == OverflowSaturate:
+		v.value = uint64Value(math.MaxUint64)
+	case v.options.overflowPolicy == OverflowWrap && neg:
+		v.value = uint64Value(-mag)
+	case v.options.overflowPolicy == OverflowWrap:
+		v.value = uint64Value(mag)
+	default:
+		v.value = value{}
+		v.err = &SyntaxError{
+			Msg: "operator \"" + op.String() + "\" overflows both int64 and uint64",
+			Pos: int(opPos),
+			Err: ErrIntegerOverflow,
+		}
+	}
+}
+
+func addUint64(x, y uint64) (r uint64, overflow bool) {
+	r = x + y
+	return r, r < x
+}
+
+func mulUint64(x, y uint64) (r uint64, overflow bool) {
+	hi, lo := bits.Mul64(x, y)
+	return lo, hi != 0
+}
+
+func shlUint64(x, y uint64) (r uint64, overflow bool) {
+	if y >= 64 {
+		return 0, x != 0
+	}
+	r = x << y
+	return r, r>>y != x
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"math"
+	"testing"
+)
+
+func TestOverflowPolicy(t *testing.T) {
+	tt := []struct {
+		in          string
+		numericType NumericType
+		expected    [3]int64 // OverflowWrap, OverflowError (0 if error), OverflowSaturate
+		expectedPos int      // position of the error if OverflowError
+	}{
+		{in: "9223372036854775807 + 1", expected: [3]int64{math.MinInt64, 0, math.MaxInt64}, expectedPos: 21},
+		{in: "-9223372036854775807 - 2", expected: [3]int64{math.MaxInt64, 0, math.MinInt64}, expectedPos: 22},
+		{in: "0 - (-9223372036854775807 - 1)", expected: [3]int64{math.MinInt64, 0, math.MaxInt64}, expectedPos: 3},
+		{in: "9223372036854775807 - -1", expected: [3]int64{math.MinInt64, 0, math.MaxInt64}, expectedPos: 21},
+		{in: "4611686018427387904 * 2", expected: [3]int64{math.MinInt64, 0, math.MaxInt64}, expectedPos: 21},
+		{in: "4611686018427387904 * -3", expected: [3]int64{4611686018427387904, 0, math.MinInt64}, expectedPos: 21},
+		{in: "(-9223372036854775807 - 1) * -1", expected: [3]int64{math.MinInt64, 0, math.MaxInt64}, expectedPos: 28},
+		{in: "(-9223372036854775807 - 1) / -1", expected: [3]int64{math.MinInt64, 0, math.MaxInt64}, expectedPos: 28},
+		{in: "-(-9223372036854775807 - 1)", expected: [3]int64{math.MinInt64, 0, math.MaxInt64}, expectedPos: 1},
+		{in: "1 << 70", expected: [3]int64{0, 0, math.MaxInt64}, expectedPos: 3},
+		{in: "-1 << 70", expected: [3]int64{0, 0, math.MinInt64}, expectedPos: 4},
+		{in: "3 << 62", expected: [3]int64{-4611686018427387904, 0, math.MaxInt64}, expectedPos: 3},
+		{in: "1 << 70", numericType: NumericTypeInt, expected: [3]int64{0, 0, math.MaxInt64}, expectedPos: 3},
+		{in: "9223372036854775807 + 1", numericType: NumericTypeInt, expected: [3]int64{math.MinInt64, 0, math.MaxInt64}, expectedPos: 21},
+		// no overflow
+		{in: "9223372036854775807 - 1", expected: [3]int64{math.MaxInt64 - 1, math.MaxInt64 - 1, math.MaxInt64 - 1}},
+		{in: "-9223372036854775807 - 1", expected: [3]int64{math.MinInt64, math.MinInt64, math.MinInt64}},
+		{in: "0 - 9223372036854775807", expected: [3]int64{-math.MaxInt64, -math.MaxInt64, -math.MaxInt64}},
+		{in: "-1 << 63", expected: [3]int64{math.MinInt64, math.MinInt64, math.MinInt64}},
+		{in: "0 << 100", expected: [3]int64{0, 0, 0}},
+		{in: "-3037000499 * 3037000499", expected: [3]int64{-9223372030926249001, -9223372030926249001, -9223372030926249001}},
+		{in: "1 >> 100", expected: [3]int64{0, 0, 0}},
+	}
+
+	for i, tc := range tt {
+		tc := tc
+		for j, policy := range [...]OverflowPolicy{OverflowWrap, OverflowError, OverflowSaturate} {
+			policy, expected := policy, tc.expected[j]
+			t.Run(fmt.Sprintf("[%d] %s %s", i, tc.in, policy), func(t *testing.T) {
+				e, err := parser.ParseExpr(tc.in)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				numericType := tc.numericType
+				if numericType == NumericTypeAuto {
+					numericType = NumericTypeGo
+				}
+				v := NewVisitor(WithNumericType(numericType), WithOverflowPolicy(policy))
+				ast.Walk(v, e)
+
+				if policy == OverflowError && tc.expectedPos != 0 {
+					var syntaxErr *SyntaxError
+					if !errors.As(v.Err(), &syntaxErr) || !errors.Is(syntaxErr, ErrIntegerOverflow) {
+						t.Fatalf("expected err: %v, got: %v", ErrIntegerOverflow, v.Err())
+					}
+					if syntaxErr.Pos != tc.expectedPos {
+						t.Fatalf("expected pos: %d, got: %d", tc.expectedPos, syntaxErr.Pos)
+					}
+					return
+				}
+				if err := v.Err(); err != nil {
+					t.Fatalf("expected nil error, got: %v", err)
+				}
+				if val := v.ValueAny(); val != expected {
+					t.Fatalf("expected val: %d, got: %v", expected, val)
+				}
+			})
+		}
+	}
+}
+
+func TestWithOverflowCheck(t *testing.T) {
+	var o options
+	WithOverflowCheck(true)(&o)
+	if o.overflowPolicy != OverflowError {
+		t.Fatalf("expected: %s, got: %s", OverflowError, o.overflowPolicy)
+	}
+	WithOverflowCheck(false)(&o)
+	if o.overflowPolicy != OverflowWrap {
+		t.Fatalf("expected: %s, got: %s", OverflowWrap, o.overflowPolicy)
+	}
+	if s := OverflowPolicy(100).String(); s != "OverflowPolicy(100)" {
+		t.Fatalf("expected: OverflowPolicy(100), got: %s", s)
+	}
+}
+
+func TestNegativeShiftCount(t *testing.T) {
+	for _, numericType := range [...]NumericType{NumericTypeAuto, NumericTypeInt, NumericTypeGo} {
+		for _, in := range [...]string{"1 << -1", "1 >> -1"} {
+			e, err := parser.ParseExpr(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v := NewVisitor(WithNumericType(numericType))
+			ast.Walk(v, e)
+			if !errors.Is(v.Err(), ErrBitwiseOperation) {
+				t.Fatalf("%s: expected err: %v, got: %v", in, ErrBitwiseOperation, v.Err())
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+
+	"github.com/muktihari/expr/internal/parse"
+)
+
+// WithFastParser directs Compile and Check to parse the expr string using expr's own parser instead of go/parser.
+// It only parses literals, identifiers, parentheses, unary and binary operators but it is faster and allocates less,
+// though not zero: the nodes are allocated in chunks since they are kept by the Program.
+// Any other syntax, including the invalid one, is parsed by go/parser so the results and errors are still the same.
+// The one-shot functions such as Any and Bool take no Option, so they always parse using go/parser, use Compile to
+// parse the expr string only once instead.
+func WithFastParser(v bool) Option {
+	return func(o *options) { o.fastParser = v }
+}
+
+// parseExpr parses str into ast.Expr based on the given options, go/parser's error is returned as SyntaxError.
+func parseExpr(str string, o *options) (ast.Expr, error) {
+	if o.fastParser {
+		if e, ok := parse.Expr(str); ok {
+			return e, nil
+		}
+	}
+	e, err := parser.ParseExpr(str)
+	if err != nil {
+		return nil, newInvalidSyntaxError(err)
+	}
+	return e, nil
+}
+
+// newInvalidSyntaxError creates SyntaxError from go/parser's error, only the first error is reported.
+func newInvalidSyntaxError(err error) error {
+	list, ok := err.(scanner.ErrorList)
+	if !ok || len(list) == 0 {
+		return err
+	}
+	return &SyntaxError{
+		Msg: list[0].Msg,
+		Pos: list[0].Pos.Offset + 1,
+		Err: ErrInvalidSyntax,
+	}
+}
@@ -0,0 +1,316 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"context"
+	"go/ast"
+	"go/token"
+	"sync"
+
+	"github.com/muktihari/expr/internal/conv"
+)
+
+// Program is a compiled expr string that can be evaluated many times without being re-parsed.
+// Program is immutable once compiled, it is safe for concurrent use by multiple goroutines.
+type Program struct {
+	expr       ast.Expr
+	code       *bytecode // expr that is lowered into bytecode.
+	options    options
+	src        string                            // expr string, it is compiled again for the typed evaluations, see typed.
+	typedProgs [NumericTypeUint + 1]typedProgram // p compiled using the NumericType of the one-shot function, see typed.
+}
+
+// typedProgram is a Program compiled once on its first typed evaluation.
+type typedProgram struct {
+	once sync.Once
+	p    *Program
+	err  error
+}
+
+// Compile parses and validates the given expr string once into a Program, its constant sub-expressions are
+// evaluated once as well, e.g. "price * (1 - 0.15)" is evaluated as "price * 0.85", unless WithMaxDepth or WithMaxNodes
+// is used so the limits are counted on the expression as written. If Option is not specified,
+// the same default options as NewVisitor will be used. e.g:
+//   - Compile("1 + 2") then p.Eval(nil) -> 3, the same as Any("1 + 2")
+//   - Compile("10 / 4 * 4") then p.EvalInt64(nil) -> 8, the same as Int64("10 / 4 * 4")
+//   - Compile("price - price*discount") then p.Eval(Env{"price": 10, "discount": 0.15}) -> 8.5
+func Compile(str string, opts ...Option) (*Program, error) {
+	o := defaultOptions()
+	for i := range opts {
+		opts[i](&o)
+	}
+	return compile(str, o)
+}
+
+// compile compiles str into a Program using given options.
+func compile(str string, o options) (*Program, error) {
+	if err := checkInputLen(len(str), o.maxInputLen, 0); err != nil {
+		return nil, err
+	}
+
+	e, err := parseExpr(str, &o)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Program{
+		expr:    e,
+		options: o,
+		src:     str,
+	}
+
+	if err := validate(p.expr, &p.options); err != nil {
+		return nil, err
+	}
+	if p.options.maxDepth == 0 && p.options.maxNodes == 0 { // limits count the nodes as written, the same as Visitor.
+		p.expr, _ = fold(p.expr, &p.options)
+	}
+	p.code = compileBytecode(p.expr)
+
+	return p, nil
+}
+
+// typed returns p compiled using given numericType, the NumericType its one-shot function uses, so the typed
+// evaluation results are the same as the one-shot function's, e.g. EvalFloat64 uses NumericTypeFloat as Float64 does.
+// If p is compiled with WithNumericType, p itself is returned.
+func (p *Program) typed(numericType NumericType) (*Program, error) {
+	if p.options.numericTypeSet || p.options.numericType == numericType {
+		return p, nil
+	}
+	t := &p.typedProgs[numericType]
+	t.once.Do(func() {
+		o := p.options
+		o.numericType = numericType
+		t.p, t.err = compile(p.src, o)
+	})
+	return t.p, t.err
+}
+
+// String returns p's expression after its constant sub-expressions are folded on Compile,
+// e.g. "price * (1 - 0.15)" -> "price * 0.85". It is meant for debugging.
+func (p *Program) String() string { return conv.FormatFoldedExpr(p.expr) }
+
+// validate reports error that would always occur regardless of the evaluated values, so it can be caught on Compile.
+func validate(e ast.Expr, o *options) (err error) {
+	ast.Inspect(e, func(node ast.Node) bool {
+		if err != nil {
+			return false
+		}
+		switch d := node.(type) {
+		case *ast.CallExpr:
+			if b, ok := lookupBuiltin(d, o); ok {
+				err = validateArgs(d, conv.FormatExpr(d.Fun), b.minArgs, b.maxArgs)
+				return err == nil
+			}
+			_, err = lookupFunc(d, o)
+			return err == nil
+		case *ast.UnaryExpr:
+			switch d.Op {
+			case token.NOT, token.ADD, token.SUB:
+			default:
+				err = &SyntaxError{
+					Msg: "operator \"" + d.Op.String() + "\" is unsupported",
+					Pos: int(d.OpPos),
+					Err: ErrUnsupportedOperator,
+				}
+				return false
+			}
+		}
+		return true
+	})
+	return err
+}
+
+// Eval evaluates p into any type it returns as a result, the same way as Any. Unless NumericTypeAuto is used,
+// float without decimal is returned as float64 instead of int64, e.g. "6.0 / 2" -> 3.0 using NumericTypeGo.
+// Identifiers are resolved using r, if r is nil, the Resolver given on Compile (if any) will be used.
+func (p *Program) Eval(r Resolver) (interface{}, error) {
+	val, err := p.visit(nil, r)
+	if err != nil {
+		return nil, err
+	}
+	return valueAsAny(val, p.options.numericType), nil
+}
+
+// EvalContext evaluates p the same way as Eval, the evaluation is aborted once ctx is done and the returned error
+// wraps ctx.Err(). See WithMaxDepth, WithMaxNodes and WithMaxStringLen to limit the evaluation as well.
+// The typed evaluations have their context variants as well, e.g. EvalBoolContext.
+func (p *Program) EvalContext(ctx context.Context, r Resolver) (interface{}, error) {
+	val, err := p.visit(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return valueAsAny(val, p.options.numericType), nil
+}
+
+// EvalBool evaluates p into boolean as a result, the same way as Bool.
+func (p *Program) EvalBool(r Resolver) (bool, error) {
+	val, err := p.visit(nil, r)
+	if err != nil {
+		return false, err
+	}
+	return valueAsBool(val)
+}
+
+// EvalBoolContext is the same as EvalBool but the evaluation is aborted once ctx is done, see EvalContext.
+func (p *Program) EvalBoolContext(ctx context.Context, r Resolver) (bool, error) {
+	val, err := p.visit(ctx, r)
+	if err != nil {
+		return false, err
+	}
+	return valueAsBool(val)
+}
+
+// EvalComplex128 evaluates p into complex128 as a result, the same way as Complex128: unless p is compiled with
+// WithNumericType, NumericTypeComplex is used.
+func (p *Program) EvalComplex128(r Resolver) (complex128, error) {
+	tp, err := p.typed(NumericTypeComplex)
+	if err != nil {
+		return 0, err
+	}
+	val, err := tp.visit(nil, r)
+	if err != nil {
+		return 0, err
+	}
+	return valueAsComplex128(val)
+}
+
+// EvalComplex128Context is the same as EvalComplex128 but the evaluation is aborted once ctx is done, see EvalContext.
+func (p *Program) EvalComplex128Context(ctx context.Context, r Resolver) (complex128, error) {
+	tp, err := p.typed(NumericTypeComplex)
+	if err != nil {
+		return 0, err
+	}
+	val, err := tp.visit(ctx, r)
+	if err != nil {
+		return 0, err
+	}
+	return valueAsComplex128(val)
+}
+
+// EvalFloat64 evaluates p into float64 as a result, the same way as Float64: unless p is compiled with
+// WithNumericType, NumericTypeFloat is used, e.g. "7 / 2" -> 3.5.
+func (p *Program) EvalFloat64(r Resolver) (float64, error) {
+	tp, err := p.typed(NumericTypeFloat)
+	if err != nil {
+		return 0, err
+	}
+	val, err := tp.visit(nil, r)
+	if err != nil {
+		return 0, err
+	}
+	return valueAsFloat64(val)
+}
+
+// EvalFloat64Context is the same as EvalFloat64 but the evaluation is aborted once ctx is done, see EvalContext.
+func (p *Program) EvalFloat64Context(ctx context.Context, r Resolver) (float64, error) {
+	tp, err := p.typed(NumericTypeFloat)
+	if err != nil {
+		return 0, err
+	}
+	val, err := tp.visit(ctx, r)
+	if err != nil {
+		return 0, err
+	}
+	return valueAsFloat64(val)
+}
+
+// EvalInt64 evaluates p into int64 as a result, the same way as Int64: unless p is compiled with
+// WithNumericType, NumericTypeInt is used, e.g. "10 / 4 * 4" -> 8.
+func (p *Program) EvalInt64(r Resolver) (int64, error) {
+	tp, err := p.typed(NumericTypeInt)
+	if err != nil {
+		return 0, err
+	}
+	val, err := tp.visit(nil, r)
+	if err != nil {
+		return 0, err
+	}
+	return valueAsInt64(val)
+}
+
+// EvalInt64Context is the same as EvalInt64 but the evaluation is aborted once ctx is done, see EvalContext.
+func (p *Program) EvalInt64Context(ctx context.Context, r Resolver) (int64, error) {
+	tp, err := p.typed(NumericTypeInt)
+	if err != nil {
+		return 0, err
+	}
+	val, err := tp.visit(ctx, r)
+	if err != nil {
+		return 0, err
+	}
+	return valueAsInt64(val)
+}
+
+// EvalUint64 evaluates p into uint64 as a result, the same way as Uint64: unless p is compiled with
+// WithNumericType, NumericTypeUint is used, e.g. "0 - 1" -> 18446744073709551615.
+func (p *Program) EvalUint64(r Resolver) (uint64, error) {
+	tp, err := p.typed(NumericTypeUint)
+	if err != nil {
+		return 0, err
+	}
+	val, err := tp.visit(nil, r)
+	if err != nil {
+		return 0, err
+	}
+	return valueAsUint64(val)
+}
+
+// EvalUint64Context is the same as EvalUint64 but the evaluation is aborted once ctx is done, see EvalContext.
+func (p *Program) EvalUint64Context(ctx context.Context, r Resolver) (uint64, error) {
+	tp, err := p.typed(NumericTypeUint)
+	if err != nil {
+		return 0, err
+	}
+	val, err := tp.visit(ctx, r)
+	if err != nil {
+		return 0, err
+	}
+	return valueAsUint64(val)
+}
+
+// EvalDecimal evaluates p into Decimal as a result, float is converted using its shortest representation: 0.1 -> 0.1.
+// It is mostly used with NumericTypeDecimal.
+func (p *Program) EvalDecimal(r Resolver) (Decimal, error) {
+	val, err := p.visit(nil, r)
+	if err != nil {
+		return Decimal{}, err
+	}
+	return valueAsDecimal(val)
+}
+
+// EvalDecimalContext is the same as EvalDecimal but the evaluation is aborted once ctx is done, see EvalContext.
+func (p *Program) EvalDecimalContext(ctx context.Context, r Resolver) (Decimal, error) {
+	val, err := p.visit(ctx, r)
+	if err != nil {
+		return Decimal{}, err
+	}
+	return valueAsDecimal(val)
+}
+
+// visit evaluates p using r as its Resolver if r is not nil, the evaluation is aborted once ctx (if any) is done.
+func (p *Program) visit(ctx context.Context, r Resolver) (value, error) {
+	o := p.options
+	if ctx != nil {
+		o.ctx = ctx
+	}
+	if r != nil {
+		o.resolver = r
+	}
+	if o.limited() { // the budget is only tracked by Visitor.
+		return visit(p.expr, o)
+	}
+	return p.code.run(o)
+}
@@ -0,0 +1,596 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/muktihari/expr"
+)
+
+func TestCompile(t *testing.T) {
+	tt := []struct {
+		In  string
+		Err error
+	}{
+		{In: "1 + 2"},
+		{In: "<-a", Err: expr.ErrUnsupportedOperator},
+		{In: "1 + ^2", Err: expr.ErrUnsupportedOperator},
+		{In: "1 + 1 + (4 == 2)"}, // only fail on evaluation
+		{In: "max(1, 2)", Err: expr.ErrFunctionCall},
+		{In: "in(1, []any{1})"},
+		{In: "in(1)", Err: expr.ErrFunctionCall},
+	}
+
+	t.Run("parser error", func(t *testing.T) {
+		p, err := expr.Compile("(1 + 1")
+		if err == nil {
+			t.Fatalf("expected error, got: %v", err)
+		}
+		if p != nil {
+			t.Fatalf("expected nil program, got: %v", p)
+		}
+	})
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.In, func(t *testing.T) {
+			_, err := expr.Compile(tc.In)
+			if !errors.Is(err, tc.Err) {
+				t.Fatalf("expected error: %v, got: %v", tc.Err, err)
+			}
+		})
+	}
+}
+
+func TestCompileWithFastParser(t *testing.T) {
+	tt := []string{
+		"price - (price * discount)",
+		"qty * 2 > 10 && !blocked || vip == \"yes\"",
+		"-(1 + 2i) * 'a' + 0x1F + .5e1",
+		"1 + (2 * \"a\")",
+		"(1 +\n2) * `raw`",
+		"in(qty, []any{1, 2}) || tier(qty) > 1", // parsed by go/parser
+		"price.amount + 1",                      // parsed by go/parser
+		"1 +",
+		"(1 + 2",
+		"1\n+ 2",
+		"1 ++ 2",
+		"\"\\x41\" + 1",
+		"0755 + 1",
+		"^1",
+	}
+
+	env := expr.Env{"price": 10.0, "discount": 0.15, "qty": 6, "blocked": false, "vip": "no"}
+	funcs := expr.WithFunctions(map[string]expr.Func{
+		"tier": {Args: []expr.Kind{expr.KindInt}, Return: expr.KindInt, Fn: func(args ...interface{}) (interface{}, error) {
+			return args[0].(int64) / 5, nil
+		}},
+	})
+
+	for _, in := range tt {
+		in := in
+		t.Run(in, func(t *testing.T) {
+			expected, err := expr.Compile(in, funcs)
+			p, fastErr := expr.Compile(in, funcs, expr.WithFastParser(true))
+			if fmt.Sprint(fastErr) != fmt.Sprint(err) {
+				t.Fatalf("expected err: %v, got: %v", err, fastErr)
+			}
+			if err != nil {
+				return
+			}
+			if p.String() != expected.String() {
+				t.Fatalf("expected: %q, got: %q", expected.String(), p.String())
+			}
+
+			expectedVal, err := expected.Eval(env)
+			val, fastErr := p.Eval(env)
+			if fmt.Sprint(fastErr) != fmt.Sprint(err) {
+				t.Fatalf("expected err: %v, got: %v", err, fastErr)
+			}
+			if val != expectedVal {
+				t.Fatalf("expected value: %v, got: %v", expectedVal, val)
+			}
+		})
+	}
+}
+
+func TestProgramEval(t *testing.T) {
+	tt := []string{
+		"2",
+		"\"2\"",
+		"2.5",
+		"4 == 2",
+		"1 + 1 + (4 == 2)",
+		"2 && 2",
+		"(2 + 2) / 10",
+		"((2 + 2) * 4 / 4) * 10.7 + 4.234567 * (50 + 50)",
+		"10 + ((-5 * -10) / -10) - 2",
+		"12.5 | 4.3",
+		"4 << 10",
+		"(2+2i) * (2+2i)",
+		"((1 < 2 && 3 > 4) || 1 == 1) && 4 < 5",
+	}
+
+	for _, s := range tt {
+		s := s
+		t.Run(s, func(t *testing.T) {
+			t.Run("Eval", func(t *testing.T) {
+				p, err := expr.Compile(s)
+				if err != nil {
+					t.Fatal(err)
+				}
+				expected, expectedErr := expr.Any(s)
+				v, err := p.Eval(nil)
+				if (err == nil) != (expectedErr == nil) {
+					t.Fatalf("expected error: %v, got: %v", expectedErr, err)
+				}
+				if v != expected {
+					t.Fatalf("expected value: %T(%v), got: %T(%v)", expected, expected, v, v)
+				}
+			})
+			t.Run("EvalBool", func(t *testing.T) {
+				p, err := expr.Compile(s)
+				if err != nil {
+					t.Fatal(err)
+				}
+				expected, expectedErr := expr.Bool(s)
+				v, err := p.EvalBool(nil)
+				if (err == nil) != (expectedErr == nil) {
+					t.Fatalf("expected error: %v, got: %v", expectedErr, err)
+				}
+				if v != expected {
+					t.Fatalf("expected value: %v, got: %v", expected, v)
+				}
+			})
+			t.Run("EvalComplex128", func(t *testing.T) {
+				p, err := expr.Compile(s, expr.WithNumericType(expr.NumericTypeComplex))
+				if err != nil {
+					t.Fatal(err)
+				}
+				expected, expectedErr := expr.Complex128(s)
+				v, err := p.EvalComplex128(nil)
+				if (err == nil) != (expectedErr == nil) {
+					t.Fatalf("expected error: %v, got: %v", expectedErr, err)
+				}
+				if v != expected && !isComplexNaN(v) {
+					t.Fatalf("expected value: %v, got: %v", expected, v)
+				}
+			})
+			t.Run("EvalFloat64", func(t *testing.T) {
+				p, err := expr.Compile(s, expr.WithNumericType(expr.NumericTypeFloat))
+				if err != nil {
+					t.Fatal(err)
+				}
+				expected, expectedErr := expr.Float64(s)
+				v, err := p.EvalFloat64(nil)
+				if (err == nil) != (expectedErr == nil) {
+					t.Fatalf("expected error: %v, got: %v", expectedErr, err)
+				}
+				if v != expected && v == v {
+					t.Fatalf("expected value: %v, got: %v", expected, v)
+				}
+			})
+			t.Run("EvalInt64", func(t *testing.T) {
+				p, err := expr.Compile(s,
+					expr.WithNumericType(expr.NumericTypeInt),
+					expr.WithAllowIntegerDividedByZero(false),
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+				expected, expectedErr := expr.Int64Strict(s)
+				v, err := p.EvalInt64(nil)
+				if (err == nil) != (expectedErr == nil) {
+					t.Fatalf("expected error: %v, got: %v", expectedErr, err)
+				}
+				if v != expected {
+					t.Fatalf("expected value: %v, got: %v", expected, v)
+				}
+			})
+		})
+	}
+}
+
+func TestProgramTypedEvalParity(t *testing.T) {
+	tt := []string{
+		"10 / 4 * 4",
+		"7 / 2",
+		"0 - 1",
+		"2.5 * 2",
+		"1 + 1 + (4 == 2)",
+		"(2 + 2) / 10",
+		"10 / 0",
+		"4 << 10",
+		"(2+2i) * (2+2i)",
+		"-9223372036854775808 - 1",
+		"18446744073709551615 + 1",
+		"((2 + 2) * 4 / 4) * 10.7 + 4.234567 * (50 + 50)",
+	}
+
+	for _, s := range tt {
+		s := s
+		t.Run(s, func(t *testing.T) {
+			p, err := expr.Compile(s) // default options, the typed evaluations use the one-shot functions' NumericType.
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			type result struct {
+				v   interface{}
+				err error
+			}
+			wrap := func(v interface{}, err error) result { return result{v, err} }
+
+			cases := map[string][2]result{
+				"EvalComplex128": {wrap(p.EvalComplex128(nil)), wrap(expr.Complex128(s))},
+				"EvalFloat64":    {wrap(p.EvalFloat64(nil)), wrap(expr.Float64(s))},
+				"EvalInt64":      {wrap(p.EvalInt64(nil)), wrap(expr.Int64(s))},
+				"EvalUint64":     {wrap(p.EvalUint64(nil)), wrap(expr.Uint64(s))},
+			}
+			for name, c := range cases {
+				got, expected := c[0], c[1]
+				if (got.err == nil) != (expected.err == nil) {
+					t.Fatalf("%s: expected error: %v, got: %v", name, expected.err, got.err)
+				}
+				if got.err != nil && got.err.Error() != expected.err.Error() {
+					t.Fatalf("%s: expected error: %v, got: %v", name, expected.err, got.err)
+				}
+				if fmt.Sprint(got.v) != fmt.Sprint(expected.v) { // NaN is not equal to itself.
+					t.Fatalf("%s: expected value: %v, got: %v", name, expected.v, got.v)
+				}
+			}
+		})
+	}
+}
+
+func TestProgramConcurrentEval(t *testing.T) {
+	p, err := expr.Compile("((2 + 2) * 4 / 4) * 10.7 + 4.234567 * (50 + 50)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				v, err := p.Eval(nil)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if v != float64(466.2567) {
+					t.Errorf("expected value: %v, got: %v", 466.2567, v)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestProgramEvalWithEnv(t *testing.T) {
+	tt := []struct {
+		In   string
+		Opts []expr.Option
+		Env  expr.Resolver
+		Eq   interface{}
+		Err  error
+	}{
+		{In: "price - price*discount", Env: expr.Env{"price": 10.0, "discount": 0.15}, Eq: float64(8.5)},
+		{In: "price - price*discount", Env: expr.Env{"price": 10, "discount": 0}, Eq: int64(10)},
+		{In: "qty * 2 > limit", Env: expr.Env{"qty": int32(6), "limit": uint8(10)}, Eq: true},
+		{In: "status == \"active\"", Env: expr.Env{"status": "active"}, Eq: true},
+		{In: "status == active", Env: expr.Env{"status": "active"}, Eq: true}, // unresolved ident is treated as usual
+		{In: "enabled && !blocked", Env: expr.Env{"enabled": true, "blocked": false}, Eq: true},
+		{In: "true", Env: expr.Env{"true": false}, Eq: false},
+		{In: "items + 1", Env: expr.Env{"items": make(chan int)}, Err: expr.ErrUnsupportedVariableType},
+		{In: "items + 1", Env: expr.Env{"items": []int{1}}, Err: expr.ErrArithmeticOperation},
+		{In: "items[0] + 1", Env: expr.Env{"items": []int{1}}, Eq: int64(2)},
+		{In: "in(2, items)", Env: expr.Env{"items": []int{1, 2}}, Eq: true},
+		{In: "x + y", Opts: []expr.Option{expr.WithEnv(map[string]interface{}{"x": 1, "y": 2})}, Eq: int64(3)},
+		{In: "x + y", Opts: []expr.Option{expr.WithEnv(map[string]interface{}{"x": 1, "y": 2})}, Env: expr.Env{"x": 10}, Err: expr.ErrArithmeticOperation}, // r replaces Compile's env
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.In, func(t *testing.T) {
+			p, err := expr.Compile(tc.In, tc.Opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := p.Eval(tc.Env)
+			if !errors.Is(err, tc.Err) {
+				t.Fatalf("expected error: %v, got: %v", tc.Err, err)
+			}
+			if v != tc.Eq {
+				t.Fatalf("expected value: %T(%v), got: %T(%v)", tc.Eq, tc.Eq, v, v)
+			}
+		})
+	}
+}
+
+func TestProgramEvalShortCircuit(t *testing.T) {
+	p, err := expr.Compile("x != 0 && 10/x > 2",
+		expr.WithNumericType(expr.NumericTypeInt),
+		expr.WithAllowIntegerDividedByZero(false),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		X  int
+		Eq bool
+	}{
+		{X: 0, Eq: false},
+		{X: 3, Eq: true},
+		{X: 5, Eq: false},
+	}
+
+	for _, tc := range tt {
+		v, err := p.EvalBool(expr.Env{"x": tc.X})
+		if err != nil {
+			t.Fatalf("x: %d, expected nil, got: %v", tc.X, err)
+		}
+		if v != tc.Eq {
+			t.Fatalf("x: %d, expected: %t, got: %t", tc.X, tc.Eq, v)
+		}
+	}
+}
+
+func TestProgramEvalNumericTypeGo(t *testing.T) {
+	tt := []struct {
+		In   string
+		Opts []expr.Option
+		Eq   interface{}
+		Err  error
+	}{
+		{In: "7 / 2", Eq: int64(3)},
+		{In: "-7 / 2", Eq: int64(-3)},
+		{In: "7 % 3", Eq: int64(1)},
+		{In: "7 / 2.0", Eq: float64(3.5)},
+		{In: "6.0 / 2", Eq: float64(3)},
+		{In: "7 / (2+0i)", Eq: complex(3.5, 0)},
+		{In: "9007199254740993 + 0", Eq: int64(9007199254740993)},
+		{In: "9007199254740993 > 9007199254740992", Eq: true},
+		{In: "4.0 << 2", Eq: int64(16)},
+		{In: "7 / 0", Eq: int64(0)},
+		{In: "7 / 0", Opts: []expr.Option{expr.WithAllowIntegerDividedByZero(false)}, Err: expr.ErrIntegerDividedByZero},
+		{In: "7 % 0", Opts: []expr.Option{expr.WithAllowIntegerDividedByZero(false)}, Err: expr.ErrIntegerDividedByZero},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.In, func(t *testing.T) {
+			p, err := expr.Compile(tc.In, append([]expr.Option{expr.WithNumericType(expr.NumericTypeGo)}, tc.Opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := p.Eval(nil)
+			if !errors.Is(err, tc.Err) {
+				t.Fatalf("expected error: %v, got: %v", tc.Err, err)
+			}
+			if v != tc.Eq {
+				t.Fatalf("expected value: %T(%v), got: %T(%v)", tc.Eq, tc.Eq, v, v)
+			}
+		})
+	}
+}
+
+func TestProgramEvalNumericTypeBig(t *testing.T) {
+	tt := []struct {
+		In  string
+		Env map[string]interface{}
+		Eq  string
+		Err error
+	}{
+		{In: "(1 << 128) - 1", Eq: "340282366920938463463374607431768211455"},
+		{In: "99999999999999999999 * 99999999999999999999", Eq: "9999999999999999999800000000000000000001"},
+		{In: "x + 1", Env: map[string]interface{}{"x": big.NewInt(math.MaxInt64)}, Eq: "9223372036854775808"},
+		{In: "x * 2", Env: map[string]interface{}{"x": big.NewRat(1, 4)}, Eq: "0.5"},
+		{In: "1 / 0.0 - 1 / 0.0", Err: expr.ErrArithmeticOperation},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.In, func(t *testing.T) {
+			p, err := expr.Compile(tc.In, expr.WithNumericType(expr.NumericTypeBig))
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := p.Eval(expr.Env(tc.Env))
+			if !errors.Is(err, tc.Err) {
+				t.Fatalf("expected error: %v, got: %v", tc.Err, err)
+			}
+			if tc.Err != nil {
+				return
+			}
+			if str := fmt.Sprint(v); str != tc.Eq {
+				t.Fatalf("expected value: %s, got: %T(%s)", tc.Eq, v, str)
+			}
+		})
+	}
+}
+
+func TestProgramEvalDecimal(t *testing.T) {
+	tt := []struct {
+		In   string
+		Opts []expr.Option
+		Env  map[string]interface{}
+		Eq   string
+		Err  error
+	}{
+		{In: "10 - 10*0.15", Eq: "8.50"},
+		{In: "price - price*discount", Env: map[string]interface{}{"price": 10, "discount": 0.15}, Eq: "8.50"},
+		{In: "100 / 3", Opts: []expr.Option{expr.WithDecimalScale(2)}, Eq: "33.33"},
+		{In: "0.125", Opts: []expr.Option{expr.WithNumericType(expr.NumericTypeFloat)}, Eq: "0.125"},
+		{In: "(1+2i)", Opts: []expr.Option{expr.WithNumericType(expr.NumericTypeComplex)}, Eq: "1"},
+		{In: "true", Err: expr.ErrValueTypeMismatch},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.In, func(t *testing.T) {
+			p, err := expr.Compile(tc.In, append([]expr.Option{expr.WithNumericType(expr.NumericTypeDecimal)}, tc.Opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := p.EvalDecimal(expr.Env(tc.Env))
+			if !errors.Is(err, tc.Err) {
+				t.Fatalf("expected error: %v, got: %v", tc.Err, err)
+			}
+			if tc.Err != nil {
+				return
+			}
+			if v.String() != tc.Eq {
+				t.Fatalf("expected value: %s, got: %s", tc.Eq, v)
+			}
+		})
+	}
+
+	p, err := expr.Compile("10 - 10*0.15", expr.WithNumericType(expr.NumericTypeDecimal))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := p.Eval(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, ok := v.(expr.Decimal); !ok || d.Float64() != 8.5 {
+		t.Fatalf("expected value: expr.Decimal(8.50), got: %T(%v)", v, v)
+	}
+}
+
+func TestProgramEvalOverflowPolicy(t *testing.T) {
+	p, err := expr.Compile("quota * 4", expr.WithNumericType(expr.NumericTypeGo), expr.WithOverflowCheck(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Eval(expr.Env{"quota": int64(math.MaxInt64)}); !errors.Is(err, expr.ErrIntegerOverflow) {
+		t.Fatalf("expected error: %v, got: %v", expr.ErrIntegerOverflow, err)
+	}
+	if v, err := p.Eval(expr.Env{"quota": 10}); err != nil || v != int64(40) {
+		t.Fatalf("expected value: 40, got: %v, err: %v", v, err)
+	}
+
+	p, err = expr.Compile("quota * 4", expr.WithNumericType(expr.NumericTypeGo), expr.WithOverflowPolicy(expr.OverflowSaturate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := p.Eval(expr.Env{"quota": int64(math.MaxInt64)}); err != nil || v != int64(math.MaxInt64) {
+		t.Fatalf("expected value: %d, got: %v, err: %v", int64(math.MaxInt64), v, err)
+	}
+}
+
+func TestProgramEvalContext(t *testing.T) {
+	t.Run("max input len", func(t *testing.T) {
+		_, err := expr.Compile("1 + 2 + 3", expr.WithMaxInputLen(8))
+		if !errors.Is(err, expr.ErrLimitExceeded) {
+			t.Fatalf("expected error: %v, got: %v", expr.ErrLimitExceeded, err)
+		}
+	})
+
+	p, err := expr.Compile("price * qty > limit", expr.WithMaxNodes(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := expr.Env{"price": 10, "qty": 2, "limit": 15}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+
+	tt := []struct {
+		Name string
+		Ctx  context.Context
+		Eq   interface{}
+		Err  error
+	}{
+		{Name: "background", Ctx: context.Background(), Err: expr.ErrLimitExceeded},
+		{Name: "canceled", Ctx: canceled, Err: context.Canceled},
+		{Name: "deadline exceeded", Ctx: expired, Err: context.DeadlineExceeded},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			v, err := p.EvalContext(tc.Ctx, env)
+			if !errors.Is(err, tc.Err) {
+				t.Fatalf("expected error: %v, got: %v", tc.Err, err)
+			}
+			if v != tc.Eq {
+				t.Fatalf("expected value: %v, got: %v", tc.Eq, v)
+			}
+		})
+	}
+
+	p, err = expr.Compile("price * qty > limit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := p.EvalContext(context.Background(), env)
+	if err != nil || v != true {
+		t.Fatalf("expected: (true, nil), got: (%v, %v)", v, err)
+	}
+
+	t.Run("typed", func(t *testing.T) {
+		pb, err := expr.Compile("price * qty > limit")
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := expr.Compile("price * qty")
+		if err != nil {
+			t.Fatal(err)
+		}
+		evals := map[string]func(ctx context.Context) (interface{}, error){
+			"EvalBoolContext":       func(ctx context.Context) (interface{}, error) { return pb.EvalBoolContext(ctx, env) },
+			"EvalComplex128Context": func(ctx context.Context) (interface{}, error) { return p.EvalComplex128Context(ctx, env) },
+			"EvalFloat64Context":    func(ctx context.Context) (interface{}, error) { return p.EvalFloat64Context(ctx, env) },
+			"EvalInt64Context":      func(ctx context.Context) (interface{}, error) { return p.EvalInt64Context(ctx, env) },
+			"EvalUint64Context":     func(ctx context.Context) (interface{}, error) { return p.EvalUint64Context(ctx, env) },
+			"EvalDecimalContext": func(ctx context.Context) (interface{}, error) {
+				d, err := p.EvalDecimalContext(ctx, env)
+				return d.String(), err
+			},
+		}
+		expected := map[string]interface{}{
+			"EvalBoolContext":       true,
+			"EvalComplex128Context": complex(20, 0),
+			"EvalFloat64Context":    float64(20),
+			"EvalInt64Context":      int64(20),
+			"EvalUint64Context":     uint64(20),
+			"EvalDecimalContext":    "20",
+		}
+
+		for name, eval := range evals {
+			if _, err := eval(canceled); !errors.Is(err, context.Canceled) {
+				t.Fatalf("%s: expected error: %v, got: %v", name, context.Canceled, err)
+			}
+			if v, err := eval(context.Background()); err != nil || v != expected[name] {
+				t.Fatalf("%s: expected: (%v, nil), got: (%v, %v)", name, expected[name], v, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,210 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"fmt"
+	"go/ast"
+	"strconv"
+
+	"github.com/muktihari/expr/internal/conv"
+)
+
+// concatString concatenates x and y where at least one of them is a string,
+// a number is only accepted if stringCoercion is true.
+func concatString(v, vx, vy *Visitor, binaryExpr *ast.BinaryExpr) {
+	x, ok := coerceString(vx.value, v.options.stringCoercion)
+	if !ok {
+		v.err = newConcatError(vx, binaryExpr.X)
+		return
+	}
+	y, ok := coerceString(vy.value, v.options.stringCoercion)
+	if !ok {
+		v.err = newConcatError(vy, binaryExpr.Y)
+		return
+	}
+	v.value = stringValue(x + y)
+}
+
+func coerceString(val value, coercion bool) (string, bool) {
+	if val.Kind() == KindString {
+		return val.String(), true
+	}
+	if coercion && isNumeric(val) {
+		return fmt.Sprint(val.Any()), true
+	}
+	return "", false
+}
+
+func newConcatError(v *Visitor, e ast.Expr) error {
+	s := conv.FormatExpr(e)
+	return &SyntaxError{
+		Msg: "could not concatenate: result of \"" + s + "\" is \"" + fmt.Sprintf("%v", v.value.Any()) + "\" which is not a string",
+		Pos: v.pos,
+		Err: ErrArithmeticOperation,
+	}
+}
+
+// visitIndex handles string indexing: "abc"[1] -> 98, the result is a byte like Go does.
+// List index is handled here as well while slice, array and map index are handled by indexObject.
+func (v *Visitor) visitIndex(indexExpr *ast.IndexExpr) ast.Visitor {
+	vx := pool.Get().(*Visitor)
+	defer pool.Put(vx)
+	vx.reset(v.options)
+
+	vx.Visit(indexExpr.X)
+	if vx.err != nil {
+		v.err = vx.err
+		return nil
+	}
+	switch vx.value.Kind() {
+	case KindString:
+	case KindObject:
+		v.indexObject(vx, indexExpr)
+		return nil
+	case KindList:
+		list := vx.value.List()
+		i, ok := v.visitIndexValue(indexExpr.Index)
+		if !ok {
+			return nil
+		}
+		if i < 0 || i >= len(list) {
+			v.missing(newIndexOutOfRangeError("index "+strconv.Itoa(i)+" is out of range with length "+strconv.Itoa(len(list)), indexExpr.Index))
+			return nil
+		}
+		v.value = list[i]
+		return nil
+	case KindNil:
+		v.missing(&SyntaxError{
+			Msg: "could not index \"" + conv.FormatExpr(indexExpr.X) + "\": result is nil",
+			Pos: int(indexExpr.Lbrack),
+			Err: ErrMissingField,
+		})
+		return nil
+	default:
+		v.err = newNonIndexableError(vx, indexExpr.X)
+		return nil
+	}
+	s := vx.value.String()
+
+	i, ok := v.visitIndexValue(indexExpr.Index)
+	if !ok {
+		return nil
+	}
+	if i < 0 || i >= len(s) {
+		v.err = newIndexOutOfRangeError("index "+strconv.Itoa(i)+" is out of range with length "+strconv.Itoa(len(s)), indexExpr.Index)
+		return nil
+	}
+	v.value = uint64Value(uint64(s[i]))
+	return nil
+}
+
+// visitSlice handles string slicing: "abc"[1:] -> "bc".
+func (v *Visitor) visitSlice(sliceExpr *ast.SliceExpr) ast.Visitor {
+	vx := pool.Get().(*Visitor)
+	defer pool.Put(vx)
+	vx.reset(v.options)
+
+	vx.Visit(sliceExpr.X)
+	if vx.err != nil {
+		v.err = vx.err
+		return nil
+	}
+	if vx.value.Kind() != KindString {
+		v.err = newNonIndexableError(vx, sliceExpr.X)
+		return nil
+	}
+	if sliceExpr.Slice3 {
+		v.err = newSlice3Error(sliceExpr)
+		return nil
+	}
+	s := vx.value.String()
+
+	low, high := 0, len(s)
+	if sliceExpr.Low != nil {
+		var ok bool
+		if low, ok = v.visitIndexValue(sliceExpr.Low); !ok {
+			return nil
+		}
+	}
+	if sliceExpr.High != nil {
+		var ok bool
+		if high, ok = v.visitIndexValue(sliceExpr.High); !ok {
+			return nil
+		}
+	}
+
+	switch {
+	case high < 0 || high > len(s):
+		v.err = newIndexOutOfRangeError("slice bounds out of range [:"+strconv.Itoa(high)+"] with length "+strconv.Itoa(len(s)), sliceExpr.High)
+		return nil
+	case low < 0 || low > high:
+		v.err = newIndexOutOfRangeError("slice bounds out of range ["+strconv.Itoa(low)+":"+strconv.Itoa(high)+"]", sliceExpr.Low)
+		return nil
+	}
+	v.value = stringValue(s[low:high])
+	return nil
+}
+
+// visitIndexValue evaluates e as an index, it must be an integer or a number without fractional part.
+func (v *Visitor) visitIndexValue(e ast.Expr) (int, bool) {
+	vi := pool.Get().(*Visitor)
+	defer pool.Put(vi)
+	vi.reset(v.options)
+
+	vi.Visit(e)
+	if vi.err != nil {
+		v.err = vi.err
+		return 0, false
+	}
+	return v.toIndex(vi, e)
+}
+
+// toIndex converts vi's value that is evaluated from e into an index.
+func (v *Visitor) toIndex(vi *Visitor, e ast.Expr) (int, bool) {
+	i, ok := convertArg(vi.value, KindInt, v.options.numericType)
+	if !ok || int64(int(i.(int64))) != i.(int64) {
+		v.err = &SyntaxError{
+			Msg: "invalid index: result of \"" + conv.FormatExpr(e) + "\" is \"" + fmt.Sprintf("%v", vi.value.Any()) + "\" which is not an integer",
+			Pos: vi.pos,
+			Err: ErrIndexOperation,
+		}
+		return 0, false
+	}
+	return int(i.(int64)), true
+}
+
+func newSlice3Error(sliceExpr *ast.SliceExpr) error {
+	return &SyntaxError{
+		Msg: "3-index slice of string \"" + conv.FormatExpr(sliceExpr.X) + "\" is not supported",
+		Pos: int(sliceExpr.Lbrack),
+		Err: ErrIndexOperation,
+	}
+}
+
+func newNonIndexableError(v *Visitor, e ast.Expr) error {
+	return &SyntaxError{
+		Msg: "result of \"" + conv.FormatExpr(e) + "\" is \"" + fmt.Sprintf("%v", v.value.Any()) + "\" which is not indexable",
+		Pos: v.pos,
+		Err: ErrIndexOperation,
+	}
+}
+
+func newIndexOutOfRangeError(msg string, e ast.Expr) error {
+	return &SyntaxError{
+		Msg: msg,
+		Pos: int(e.Pos()),
+		Err: ErrIndexOutOfRange,
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"testing"
+)
+
+func TestStringOperators(t *testing.T) {
+	env := map[string]interface{}{
+		"name":  "expr",
+		"count": 3,
+		"ratio": 0.5,
+	}
+
+	tt := []struct {
+		in           string
+		options      []Option
+		expectedKind Kind
+		expectedStr  string
+		expectedErr  error
+		expectedPos  int
+	}{
+		{in: `"foo" + "bar"`, expectedKind: KindString, expectedStr: "foobar"},
+		{in: `"foo" + "" + "bar"`, expectedKind: KindString, expectedStr: "foobar"},
+		{in: `name + "-" + name`, expectedKind: KindString, expectedStr: "expr-expr"},
+		{in: `("a" + "b") == "ab"`, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `"n=" + 1`, expectedErr: ErrArithmeticOperation, expectedPos: 8},
+		{in: `1 + "n"`, expectedErr: ErrArithmeticOperation, expectedPos: 1},
+		{in: `"n" + true`, expectedErr: ErrArithmeticOperation, expectedPos: 7},
+		{in: `"n" - "m"`, expectedErr: ErrArithmeticOperation, expectedPos: 1},
+		{in: `"n=" + count`, options: []Option{WithStringCoercion(true)}, expectedKind: KindString, expectedStr: "n=3"},
+		{in: `"r=" + ratio`, options: []Option{WithStringCoercion(true)}, expectedKind: KindString, expectedStr: "r=0.5"},
+		{in: `count + " items"`, options: []Option{WithStringCoercion(true)}, expectedKind: KindString, expectedStr: "3 items"},
+		{in: `"n=" + (1 + 1)`, options: []Option{WithStringCoercion(true)}, expectedKind: KindString, expectedStr: "n=2"},
+		{in: `"n=" + 1 + 1`, options: []Option{WithStringCoercion(true)}, expectedKind: KindString, expectedStr: "n=11"},
+		{in: `"n=" + true`, options: []Option{WithStringCoercion(true)}, expectedErr: ErrArithmeticOperation, expectedPos: 8},
+		// index
+		{in: `"abc"[0]`, expectedKind: KindUint, expectedStr: "97"},
+		{in: `"abc"[1] == 'b'`, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: `name["1"]`, expectedErr: ErrIndexOperation, expectedPos: 6},
+		{in: `name[3.0]`, expectedKind: KindUint, expectedStr: "114"},
+		{in: `name[1.5]`, expectedErr: ErrIndexOperation, expectedPos: 6},
+		{in: `name[4]`, expectedErr: ErrIndexOutOfRange, expectedPos: 6},
+		{in: `name[-1]`, expectedErr: ErrIndexOutOfRange, expectedPos: 6},
+		{in: `name[1] + 1`, options: []Option{WithNumericType(NumericTypeGo)}, expectedKind: KindUint, expectedStr: "121"},
+		{in: `name[1]`, options: []Option{WithNumericType(NumericTypeDecimal)}, expectedKind: KindUint, expectedStr: "120"},
+		{in: `count[0]`, expectedErr: ErrIndexOperation, expectedPos: 1},
+		{in: `"abc"[1 / 0]`, options: []Option{WithNumericType(NumericTypeInt), WithAllowIntegerDividedByZero(false)}, expectedErr: ErrIntegerDividedByZero},
+		// slice
+		{in: `"abcdef"[1:3]`, expectedKind: KindString, expectedStr: "bc"},
+		{in: `"abcdef"[:3]`, expectedKind: KindString, expectedStr: "abc"},
+		{in: `"abcdef"[3:]`, expectedKind: KindString, expectedStr: "def"},
+		{in: `"abcdef"[:]`, expectedKind: KindString, expectedStr: "abcdef"},
+		{in: `"abcdef"[6:]`, expectedKind: KindString, expectedStr: ""},
+		{in: `name[1:] + "!"`, expectedKind: KindString, expectedStr: "xpr!"},
+		{in: `name[:count]`, expectedKind: KindString, expectedStr: "exp"},
+		{in: `name[:5]`, expectedErr: ErrIndexOutOfRange, expectedPos: 7},
+		{in: `name[3:2]`, expectedErr: ErrIndexOutOfRange, expectedPos: 6},
+		{in: `name[5:]`, expectedErr: ErrIndexOutOfRange, expectedPos: 6},
+		{in: `name[-1:]`, expectedErr: ErrIndexOutOfRange, expectedPos: 6},
+		{in: `name[0:1:2]`, expectedErr: ErrIndexOperation, expectedPos: 5},
+		{in: `name["a":]`, expectedErr: ErrIndexOperation, expectedPos: 6},
+		{in: `name[:"a"]`, expectedErr: ErrIndexOperation, expectedPos: 7},
+		{in: `true[1:]`, expectedErr: ErrIndexOperation, expectedPos: 1},
+		{in: `(1 + true)[1:]`, expectedErr: ErrArithmeticOperation},
+		{in: `(1 + true)[1]`, expectedErr: ErrArithmeticOperation},
+	}
+
+	for i, tc := range tt {
+		tc := tc
+		t.Run(fmt.Sprintf("[%d] %s", i, tc.in), func(t *testing.T) {
+			e, err := parser.ParseExpr(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := NewVisitor(append([]Option{WithEnv(env)}, tc.options...)...)
+			ast.Walk(v, e)
+
+			if err := v.Err(); !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected err: %v, got: %v", tc.expectedErr, err)
+			}
+			if tc.expectedPos != 0 {
+				var syntaxErr *SyntaxError
+				if !errors.As(v.Err(), &syntaxErr) || syntaxErr.Pos != tc.expectedPos {
+					t.Fatalf("expected pos: %d, got: %v", tc.expectedPos, v.Err())
+				}
+			}
+			if tc.expectedErr != nil {
+				return
+			}
+			if v.Kind() != tc.expectedKind {
+				t.Fatalf("expected kind: %s, got: %s", tc.expectedKind, v.Kind())
+			}
+			if str := fmt.Sprint(v.ValueAny()); str != tc.expectedStr {
+				t.Fatalf("expected val: %s, got: %s", tc.expectedStr, str)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"go/ast"
+	"go/token"
+	"math"
+	"strconv"
+)
+
+// isUint reports whether val is an uint64.
+func isUint(val value) bool { return val.Kind() == KindUint }
+
+// parseUint converts numeric val into uint64, negative integer is converted using two's complement: -1 -> MaxUint64.
+func parseUint(val value) uint64 {
+	switch val.Kind() {
+	case KindUint:
+		return val.Uint64()
+	case KindInt, KindRune:
+		return uint64(val.Int64())
+	case KindFloat:
+		return float64ToUint64(val.Float64())
+	case KindImag:
+		return float64ToUint64(real(val.Complex128()))
+	case KindBigInt:
+		return val.BigInt().Uint64()
+	case KindBigFloat:
+		return float64ToUint64(parseFloat(val))
+	case KindDecimal:
+		return uint64(parseInt(val))
+	}
+	return 0
+}
+
+func float64ToUint64(f float64) uint64 {
+	if f < 0 {
+		return uint64(int64(f))
+	}
+	return uint64(f)
+}
+
+// convertToUint64 converts integer val into uint64, float is accepted only if it doesn't have decimal.
+// Negative value is converted using two's complement: -1 -> MaxUint64.
+func convertToUint64(val value) (uint64, bool) {
+	switch val.Kind() {
+	case KindUint:
+		return val.Uint64(), true
+	case KindInt, KindRune:
+		return uint64(val.Int64()), true
+	case KindFloat:
+		f := val.Float64()
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxUint64 {
+			return 0, false
+		}
+		return float64ToUint64(f), true
+	}
+	return 0, false
+}
+
+// calculateMixedUint calculates integers where at least one of them is an uint64 and the other is an int64 exactly,
+// see setMixedInt. Both uint64 are calculated as uint64 and wrap around like Go.
+func calculateMixedUint(v, vx, vy *Visitor, binaryExpr *ast.BinaryExpr) {
+	if isUint(vx.value) && isUint(vy.value) {
+		calculateUint(v, vx.value.Uint64(), vy.value.Uint64(), vy.pos, binaryExpr.Op, binaryExpr.OpPos)
+		return
+	}
+
+	xneg, x := signMagnitude(vx.value)
+	yneg, y := signMagnitude(vy.value)
+
+	var (
+		neg      bool
+		r        uint64
+		overflow bool
+	)
+	switch binaryExpr.Op {
+	case token.ADD, token.SUB:
+		if binaryExpr.Op == token.SUB {
+			yneg = !yneg
+		}
+		switch {
+		case xneg == yneg:
+			r, overflow = addUint64(x, y)
+			neg = xneg
+		case x >= y:
+			r, neg = x-y, xneg
+		default:
+			r, neg = y-x, yneg
+		}
+	case token.MUL:
+		r, overflow = mulUint64(x, y)
+		neg = xneg != yneg
+	case token.QUO, token.REM:
+		if y == 0 {
+			calculateUint(v, 0, 0, vy.pos, binaryExpr.Op, binaryExpr.OpPos) // divided by zero
+			return
+		}
+		if binaryExpr.Op == token.QUO {
+			r, neg = x/y, xneg != yneg
+		} else {
+			r, neg = x%y, xneg // truncated like Go
+		}
+	}
+	setMixedInt(v, neg && (r != 0 || overflow), r, overflow, binaryExpr.Op, binaryExpr.OpPos) // no negative zero.
+}
+
+// signMagnitude splits integer val into its sign and its magnitude, val must be an int64 or an uint64.
+func signMagnitude(val value) (neg bool, mag uint64) {
+	if isUint(val) {
+		return false, val.Uint64()
+	}
+	i := val.Int64()
+	if i < 0 {
+		return true, uint64(-i) // -MinInt64 wraps around into MinInt64 whose uint64 is 1 << 63.
+	}
+	return false, uint64(i)
+}
+
+func calculateUint(v *Visitor, x, y uint64, yPos int, op token.Token, opPos token.Pos) {
+	v.value.SetKind(KindUint)
+	switch op {
+	case token.ADD:
+		r, overflow := addUint64(x, y)
+		setUint64(v, r, overflow, true, op, opPos)
+	case token.SUB:
+		setUint64(v, x-y, x < y, false, op, opPos)
+	case token.MUL:
+		r, overflow := mulUint64(x, y)
+		setUint64(v, r, overflow, true, op, opPos)
+	case token.QUO, token.REM:
+		if y == 0 {
+			if v.options.allowIntegerDividedByZero {
+				v.value = uint64Value(0)
+				return
+			}
+			v.value = value{}
+			v.err = &SyntaxError{
+				Msg: "could not divide x with zero y, allowIntegerDividedByZero == false",
+				Pos: yPos,
+				Err: ErrIntegerDividedByZero,
+			}
+			return
+		}
+		if op == token.QUO {
+			v.value = uint64Value(x / y)
+			return
+		}
+		v.value = uint64Value(x % y)
+	}
+}
+
+// compareUint compares numbers where at least one of them is an uint64, integers are compared exactly.
+func compareUint(v, vx, vy *Visitor, binaryExpr *ast.BinaryExpr) {
+	switch {
+	case vx.value.Kind() == KindImag || vy.value.Kind() == KindImag:
+		compareComplex(v, parseComplex(vx.value), parseComplex(vy.value), binaryExpr.Op, binaryExpr.OpPos)
+	case vx.value.Kind() == KindFloat || vy.value.Kind() == KindFloat:
+		compareFloat(v, parseFloat(vx.value), parseFloat(vy.value), binaryExpr.Op)
+	case vx.value.Kind() == KindInt && vx.value.Int64() < 0:
+		compareInt(v, -1, 0, binaryExpr) // negative x is less than any uint64
+	case vy.value.Kind() == KindInt && vy.value.Int64() < 0:
+		compareInt(v, 0, -1, binaryExpr)
+	default:
+		x, y := parseUint(vx.value), parseUint(vy.value)
+		switch binaryExpr.Op {
+		case token.EQL:
+			v.value = boolValue(x == y)
+		case token.NEQ:
+			v.value = boolValue(x != y)
+		case token.GTR:
+			v.value = boolValue(x > y)
+		case token.GEQ:
+			v.value = boolValue(x >= y)
+		case token.LSS:
+			v.value = boolValue(x < y)
+		case token.LEQ:
+			v.value = boolValue(x <= y)
+		}
+	}
+}
+
+// bitwiseUint does bitwise operation where the result is an uint64: both operands are uint64 for [&, |, ^, &^]
+// (int64 is converted using two's complement), while for [<<, >>] the left operand is uint64 and the shift is logical.
+func bitwiseUint(v, vx, vy *Visitor, binaryExpr *ast.BinaryExpr) {
+	var x, y uint64
+	if v.options.numericType == NumericTypeUint {
+		x, y = parseUint(vx.value), parseUint(vy.value)
+	} else {
+		var ok bool
+		x, ok = convertToUint64(vx.value)
+		if !ok {
+			v.err = newBitwiseNonIntegerError(vx, binaryExpr.X)
+			return
+		}
+		y, ok = convertToUint64(vy.value)
+		if !ok {
+			v.err = newBitwiseNonIntegerError(vy, binaryExpr.Y)
+			return
+		}
+	}
+
+	v.value.SetKind(KindUint)
+	switch binaryExpr.Op {
+	case token.AND:
+		v.value = uint64Value(x & y)
+	case token.OR:
+		v.value = uint64Value(x | y)
+	case token.XOR:
+		v.value = uint64Value(x ^ y)
+	case token.AND_NOT:
+		v.value = uint64Value(x &^ y)
+	case token.SHL, token.SHR:
+		if v.options.numericType != NumericTypeUint && parseFloat(vy.value) < 0 {
+			v.value = value{}
+			v.err = &SyntaxError{
+				Msg: "invalid shift count \"" + strconv.FormatInt(parseInt(vy.value), 10) + "\"",
+				Pos: vy.pos,
+				Err: ErrBitwiseOperation,
+			}
+			return
+		}
+		if binaryExpr.Op == token.SHL {
+			r, overflow := shlUint64(x, y)
+			setUint64(v, r, overflow, true, binaryExpr.Op, binaryExpr.OpPos)
+			return
+		}
+		v.value = uint64Value(x >> y)
+	}
+}
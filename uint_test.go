@@ -0,0 +1,186 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"testing"
+)
+
+func TestNumericTypeUint(t *testing.T) {
+	tt := []struct {
+		in           string
+		options      []Option
+		expectedKind Kind
+		expectedStr  string
+		expectedErr  error
+	}{
+		{in: "18446744073709551615", expectedKind: KindUint, expectedStr: "18446744073709551615"},
+		{in: "0 - 1", expectedKind: KindUint, expectedStr: "18446744073709551615"},
+		{in: "-1", expectedKind: KindUint, expectedStr: "18446744073709551615"},
+		{in: "18446744073709551615 + 1", expectedKind: KindUint, expectedStr: "0"},
+		{in: "9223372036854775808 * 2", expectedKind: KindUint, expectedStr: "0"},
+		{in: "7 / 2", expectedKind: KindUint, expectedStr: "3"},
+		{in: "7 % 2", expectedKind: KindUint, expectedStr: "1"},
+		{in: "7.9 / 2", expectedKind: KindUint, expectedStr: "3"},
+		{in: "7 / 0", expectedKind: KindUint, expectedStr: "0"},
+		{in: "7 / 0", options: []Option{WithAllowIntegerDividedByZero(false)}, expectedErr: ErrIntegerDividedByZero},
+		{in: "7 % 0", options: []Option{WithAllowIntegerDividedByZero(false)}, expectedErr: ErrIntegerDividedByZero},
+		{in: "0 - 1 > 1", expectedKind: KindBoolean, expectedStr: "true"},
+		{in: "18446744073709551615 == 18446744073709551615", expectedKind: KindBoolean, expectedStr: "true"},
+		{in: "0xFFFFFFFFFFFFFFFF >> 60", expectedKind: KindUint, expectedStr: "15"},
+		{in: "1 << 63 >> 63", expectedKind: KindUint, expectedStr: "1"},
+		{in: "1 << 64", expectedKind: KindUint, expectedStr: "0"},
+		{in: "0xFF00 & 0x0FF0 | 0x000F", expectedKind: KindUint, expectedStr: "3855"},
+		{in: "0xFF &^ 0x0F", expectedKind: KindUint, expectedStr: "240"},
+		{in: "1 + true", expectedErr: ErrArithmeticOperation},
+		// overflow policy
+		{in: "0 - 1", options: []Option{WithOverflowPolicy(OverflowError)}, expectedErr: ErrIntegerOverflow},
+		{in: "0 - 1", options: []Option{WithOverflowPolicy(OverflowSaturate)}, expectedKind: KindUint, expectedStr: "0"},
+		{in: "18446744073709551615 + 1", options: []Option{WithOverflowPolicy(OverflowError)}, expectedErr: ErrIntegerOverflow},
+		{in: "18446744073709551615 + 1", options: []Option{WithOverflowPolicy(OverflowSaturate)}, expectedKind: KindUint, expectedStr: "18446744073709551615"},
+		{in: "9223372036854775808 * 2", options: []Option{WithOverflowPolicy(OverflowError)}, expectedErr: ErrIntegerOverflow},
+		{in: "3 << 63", options: []Option{WithOverflowPolicy(OverflowError)}, expectedErr: ErrIntegerOverflow},
+		{in: "3 << 63", options: []Option{WithOverflowPolicy(OverflowSaturate)}, expectedKind: KindUint, expectedStr: "18446744073709551615"},
+	}
+
+	for i, tc := range tt {
+		tc := tc
+		t.Run(fmt.Sprintf("[%d] %s", i, tc.in), func(t *testing.T) {
+			e, err := parser.ParseExpr(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := NewVisitor(append([]Option{WithNumericType(NumericTypeUint)}, tc.options...)...)
+			ast.Walk(v, e)
+
+			if err := v.Err(); !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected err: %v, got: %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr != nil {
+				return
+			}
+			if v.Kind() != tc.expectedKind {
+				t.Fatalf("expected kind: %s, got: %s", tc.expectedKind, v.Kind())
+			}
+			if str := fmt.Sprint(v.ValueAny()); str != tc.expectedStr {
+				t.Fatalf("expected val: %s, got: %s", tc.expectedStr, str)
+			}
+		})
+	}
+}
+
+func TestUintOperandInOtherNumericTypes(t *testing.T) {
+	env := map[string]interface{}{
+		"u":   uint64(18446744073709551615),
+		"s":   uint(10),
+		"u8":  uint8(255),
+		"u32": uint32(7),
+	}
+
+	tt := []struct {
+		in           string
+		numericType  NumericType
+		options      []Option
+		expectedKind Kind
+		expectedStr  string
+		expectedErr  error
+	}{
+		{in: "18446744073709551615", numericType: NumericTypeGo, expectedKind: KindUint, expectedStr: "18446744073709551615"},
+		{in: "18446744073709551615 - 1", numericType: NumericTypeGo, expectedKind: KindUint, expectedStr: "18446744073709551614"},
+		{in: "s + 1", numericType: NumericTypeGo, expectedKind: KindUint, expectedStr: "11"},
+		{in: "s + u8 + u32", numericType: NumericTypeGo, expectedKind: KindUint, expectedStr: "272"},
+		{in: "s - -1", numericType: NumericTypeGo, expectedKind: KindUint, expectedStr: "11"},
+		{in: "s * -2", numericType: NumericTypeGo, expectedKind: KindInt, expectedStr: "-20"},
+		{in: "u - -1", numericType: NumericTypeGo, expectedKind: KindUint, expectedStr: "0"}, // wrap around like Go's uint64
+		{in: "u + 1", numericType: NumericTypeGo, expectedKind: KindUint, expectedStr: "0"},
+		{in: "u + 1", numericType: NumericTypeGo, options: []Option{WithOverflowPolicy(OverflowError)}, expectedErr: ErrIntegerOverflow},
+		{in: "u + 1", numericType: NumericTypeGo, options: []Option{WithOverflowPolicy(OverflowSaturate)}, expectedKind: KindUint, expectedStr: "18446744073709551615"},
+		{in: "u + s", numericType: NumericTypeGo, expectedKind: KindUint, expectedStr: "9"}, // both uint64 wrap around like Go
+		{in: "s - 11", numericType: NumericTypeGo, expectedKind: KindInt, expectedStr: "-1"},
+		{in: "s - 11", numericType: NumericTypeGo, options: []Option{WithOverflowPolicy(OverflowError)}, expectedKind: KindInt, expectedStr: "-1"},
+		{in: "1 - 9223372036854775808", expectedKind: KindInt, expectedStr: "-9223372036854775807"},
+		{in: "1 - 9223372036854775808", numericType: NumericTypeGo, expectedKind: KindInt, expectedStr: "-9223372036854775807"},
+		{in: "1 - 9223372036854775809", expectedKind: KindInt, expectedStr: "-9223372036854775808"},
+		{in: "-2 - 9223372036854775808", expectedKind: KindUint, expectedStr: "9223372036854775806"},
+		{in: "-2 - 9223372036854775808", options: []Option{WithOverflowPolicy(OverflowError)}, expectedErr: ErrIntegerOverflow},
+		{in: "-2 - 9223372036854775808", options: []Option{WithOverflowPolicy(OverflowSaturate)}, expectedKind: KindInt, expectedStr: "-9223372036854775808"},
+		{in: "0 - 0xFFFFFFFFFFFFFFFF", expectedKind: KindUint, expectedStr: "1"},
+		{in: "0 - 0xFFFFFFFFFFFFFFFF", numericType: NumericTypeGo, expectedKind: KindUint, expectedStr: "1"},
+		{in: "0 - 0xFFFFFFFFFFFFFFFF", options: []Option{WithOverflowPolicy(OverflowError)}, expectedErr: ErrIntegerOverflow},
+		{in: "0 - 0xFFFFFFFFFFFFFFFF", options: []Option{WithOverflowPolicy(OverflowSaturate)}, expectedKind: KindInt, expectedStr: "-9223372036854775808"},
+		{in: "9223372036854775808 * 2", expectedKind: KindUint, expectedStr: "0"},
+		{in: "9223372036854775808 * 2", numericType: NumericTypeGo, options: []Option{WithOverflowPolicy(OverflowError)}, expectedErr: ErrIntegerOverflow},
+		{in: "9223372036854775808 * 2", options: []Option{WithOverflowPolicy(OverflowSaturate)}, expectedKind: KindUint, expectedStr: "18446744073709551615"},
+		{in: "9223372036854775808 * -2", options: []Option{WithOverflowPolicy(OverflowSaturate)}, expectedKind: KindInt, expectedStr: "-9223372036854775808"},
+		{in: "9223372036854775808 * -1", expectedKind: KindInt, expectedStr: "-9223372036854775808"},
+		{in: "9223372036854775808 * 0", expectedKind: KindUint, expectedStr: "0"},
+		{in: "9223372036854775808 / -2", expectedKind: KindInt, expectedStr: "-4611686018427387904"},
+		{in: "-7 % 9223372036854775808", expectedKind: KindInt, expectedStr: "-7"},
+		{in: "9223372036854775809 % -2", expectedKind: KindUint, expectedStr: "1"},
+		{in: "s / 0", numericType: NumericTypeGo, options: []Option{WithAllowIntegerDividedByZero(false)}, expectedErr: ErrIntegerDividedByZero},
+		{in: "s + 0.5", numericType: NumericTypeGo, expectedKind: KindFloat, expectedStr: "10.5"},
+		{in: "u > 9223372036854775807", numericType: NumericTypeGo, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: "-1 < u", numericType: NumericTypeGo, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: "u == -1", numericType: NumericTypeGo, expectedKind: KindBoolean, expectedStr: "false"},
+		{in: "s == 10.0", numericType: NumericTypeGo, expectedKind: KindBoolean, expectedStr: "true"},
+		{in: "u >> 63", numericType: NumericTypeGo, expectedKind: KindUint, expectedStr: "1"},
+		{in: "u & -256", numericType: NumericTypeGo, expectedKind: KindUint, expectedStr: "18446744073709551360"},
+		{in: "-1 >> s", numericType: NumericTypeGo, expectedKind: KindInt, expectedStr: "-1"},
+		{in: "s << -1", numericType: NumericTypeGo, expectedErr: ErrBitwiseOperation},
+		{in: "s & 1.5", numericType: NumericTypeGo, expectedErr: ErrBitwiseOperation},
+		{in: "-s", numericType: NumericTypeGo, expectedKind: KindInt, expectedStr: "-10"},
+		{in: "-9223372036854775808", expectedKind: KindInt, expectedStr: "-9223372036854775808"},
+		{in: "-9223372036854775808", numericType: NumericTypeGo, expectedKind: KindInt, expectedStr: "-9223372036854775808"},
+		{in: "-0xFFFFFFFFFFFFFFFF", expectedKind: KindUint, expectedStr: "1"},
+		{in: "-u", numericType: NumericTypeGo, expectedKind: KindUint, expectedStr: "1"},
+		{in: "-u", numericType: NumericTypeGo, options: []Option{WithOverflowPolicy(OverflowError)}, expectedErr: ErrIntegerOverflow},
+		{in: "-u", numericType: NumericTypeGo, options: []Option{WithOverflowPolicy(OverflowSaturate)}, expectedKind: KindInt, expectedStr: "-9223372036854775808"},
+		{in: "s + 1", numericType: NumericTypeInt, expectedKind: KindInt, expectedStr: "11"},
+		{in: "u + 1", numericType: NumericTypeFloat, expectedKind: KindFloat, expectedStr: "1.8446744073709552e+19"},
+		{in: "s + 1", numericType: NumericTypeBig, expectedKind: KindBigInt, expectedStr: "11"},
+		{in: "u + 1", numericType: NumericTypeBig, expectedKind: KindBigInt, expectedStr: "18446744073709551616"},
+		{in: "s / 4", numericType: NumericTypeDecimal, expectedKind: KindDecimal, expectedStr: "2.5"},
+	}
+
+	for i, tc := range tt {
+		tc := tc
+		t.Run(fmt.Sprintf("[%d] %s", i, tc.in), func(t *testing.T) {
+			e, err := parser.ParseExpr(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := NewVisitor(append([]Option{WithNumericType(tc.numericType), WithEnv(env)}, tc.options...)...)
+			ast.Walk(v, e)
+
+			if err := v.Err(); !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected err: %v, got: %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr != nil {
+				return
+			}
+			if v.Kind() != tc.expectedKind {
+				t.Fatalf("expected kind: %s, got: %s", tc.expectedKind, v.Kind())
+			}
+			if str := fmt.Sprint(v.ValueAny()); str != tc.expectedStr {
+				t.Fatalf("expected val: %s, got: %s", tc.expectedStr, str)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package expr
 
 import (
 	"math"
+	"math/big"
 	"strconv"
 )
 
@@ -14,21 +15,40 @@ const (
 
 	// Identifiers of numeric type
 	numeric_beg
-	KindInt   // 12345
-	KindFloat // 123.45
-	KindImag  // 123.45i
+	KindInt      // 12345
+	KindFloat    // 123.45
+	KindImag     // 123.45i
+	KindBigInt   // 12345 (*big.Int, only if NumericTypeBig or resolved from variable)
+	KindBigFloat // 123.45 (*big.Float, only if NumericTypeBig or resolved from variable)
+	KindDecimal  // 123.45 (Decimal, only if NumericTypeDecimal or resolved from variable)
+	KindUint     // 12345 (uint64, only if NumericTypeUint, integer literal > MaxInt64 or resolved from variable)
+	KindRune     // 'a' (rune, only from char literal, it behaves as an integer in arithmetic and comparison)
 	numeric_end
 
 	KindString // "abc" 'abc' `abc`
+	KindNil    // nil, nil variable, nil element or missing field (see WithMissingAsNil)
+	KindObject // map, slice, array or struct (only resolved from variable)
+	KindList   // []any{1, "a", true}
+
+	KindAny // any kind, only used for declaring Func's arguments and return value, and Check's Schema
 )
 
 var kinds = [...]string{
-	KindIllegal: "KindIllegal",
-	KindBoolean: "KindBoolean",
-	KindInt:     "KindInt",
-	KindFloat:   "KindFloat",
-	KindImag:    "KindImag",
-	KindString:  "KindString",
+	KindIllegal:  "KindIllegal",
+	KindBoolean:  "KindBoolean",
+	KindInt:      "KindInt",
+	KindFloat:    "KindFloat",
+	KindImag:     "KindImag",
+	KindBigInt:   "KindBigInt",
+	KindBigFloat: "KindBigFloat",
+	KindDecimal:  "KindDecimal",
+	KindUint:     "KindUint",
+	KindRune:     "KindRune",
+	KindString:   "KindString",
+	KindNil:      "KindNil",
+	KindObject:   "KindObject",
+	KindList:     "KindList",
+	KindAny:      "KindAny",
 }
 
 func (k Kind) String() string {
@@ -39,11 +59,11 @@ func (k Kind) String() string {
 }
 
 // value is a custom value to reduce memory allocation,
-// so we don't allocate if the value is bool, int64 or float64.
+// so we don't allocate if the value is bool, int64, uint64 or float64.
 type value struct {
 	_   [0]func()   // disallow ==
-	num uint64      // storage for bool, int64 or float64 value.
-	any interface{} // storage for Kind (only if bool, int64 or float64), complex128 value or string value.
+	num uint64      // storage for bool, int64, uint64, float64 or rune value.
+	any interface{} // storage for Kind (only if bool, int64, uint64, float64 or rune), complex128, *big.Int, *big.Float, Decimal, string, object or list value.
 }
 
 // Kind returns value's kind.
@@ -55,6 +75,16 @@ func (v *value) Kind() Kind {
 		return KindImag
 	case string:
 		return KindString
+	case *big.Int:
+		return KindBigInt
+	case *big.Float:
+		return KindBigFloat
+	case Decimal:
+		return KindDecimal
+	case object:
+		return KindObject
+	case []value:
+		return KindList
 	}
 	return KindIllegal
 }
@@ -68,6 +98,9 @@ func (v *value) Bool() bool { return v.num == 1 }
 // Int64 returns value as int64.
 func (v *value) Int64() int64 { return int64(v.num) }
 
+// Uint64 returns value as uint64.
+func (v *value) Uint64() uint64 { return v.num }
+
 // Float64 returns value as float64.
 func (v *value) Float64() float64 { return math.Float64frombits(v.num) }
 
@@ -77,6 +110,36 @@ func (v *value) Complex128() complex128 {
 	return val
 }
 
+// BigInt returns value as *big.Int, it must not be modified.
+func (v *value) BigInt() *big.Int {
+	val, _ := v.any.(*big.Int)
+	return val
+}
+
+// BigFloat returns value as *big.Float, it must not be modified.
+func (v *value) BigFloat() *big.Float {
+	val, _ := v.any.(*big.Float)
+	return val
+}
+
+// Decimal returns value as Decimal.
+func (v *value) Decimal() Decimal {
+	val, _ := v.any.(Decimal)
+	return val
+}
+
+// Object returns value as Go's composite value, it must not be modified.
+func (v *value) Object() interface{} {
+	o, _ := v.any.(object)
+	return o.x
+}
+
+// List returns value as list, it must not be modified.
+func (v *value) List() []value {
+	list, _ := v.any.([]value)
+	return list
+}
+
 // String returns value as string.
 func (v *value) String() string {
 	s, _ := v.any.(string)
@@ -90,14 +153,29 @@ func (v *value) Any() interface{} {
 		return v.num == 1
 	case KindInt:
 		return int64(v.num)
+	case KindUint:
+		return v.num
+	case KindRune:
+		return rune(v.num)
 	case KindFloat:
 		return math.Float64frombits(v.num)
 	case KindImag:
 		v, _ := v.any.(complex128)
 		return v
+	case KindBigInt, KindBigFloat, KindDecimal:
+		return v.any
 	case KindString:
 		s, _ := v.any.(string)
 		return s
+	case KindObject:
+		return v.Object()
+	case KindList:
+		list := v.List()
+		s := make([]interface{}, len(list))
+		for i := range list {
+			s[i] = list[i].Any()
+		}
+		return s
 	}
 	return nil
 }
@@ -114,6 +192,20 @@ func boolValue(v bool) value {
 // int64Value creates int64 value.
 func int64Value(v int64) value { return value{num: uint64(v), any: KindInt} }
 
+// uint64Value creates uint64 value.
+func uint64Value(v uint64) value { return value{num: v, any: KindUint} }
+
+// runeValue creates rune value.
+func runeValue(v rune) value { return value{num: uint64(v), any: KindRune} }
+
+// runeAsInt converts rune val into int64 value so it behaves as an integer, other kinds are returned as is.
+func runeAsInt(val value) value {
+	if val.Kind() == KindRune {
+		return int64Value(val.Int64())
+	}
+	return val
+}
+
 // float64Value creates float64 value.
 func float64Value(v float64) value { return value{num: math.Float64bits(v), any: KindFloat} }
 
@@ -122,3 +214,12 @@ func complex128Value(v complex128) value { return value{any: v} }
 
 // stringValue creates string value.
 func stringValue(v string) value { return value{any: v} }
+
+// bigIntValue creates *big.Int value, v must not be modified afterward.
+func bigIntValue(v *big.Int) value { return value{any: v} }
+
+// bigFloatValue creates *big.Float value, v must not be modified afterward.
+func bigFloatValue(v *big.Float) value { return value{any: v} }
+
+// decimalValue creates Decimal value.
+func decimalValue(v Decimal) value { return value{any: v} }
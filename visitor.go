@@ -14,12 +14,17 @@
 package expr
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/token"
+	"math"
+	"math/big"
 	"strconv"
 	"strings"
 	"sync"
+	"unicode/utf8"
 
 	"github.com/muktihari/expr/internal/conv"
 )
@@ -34,11 +39,35 @@ const (
 	NumericTypeComplex                    // [1 * 2 = 2+0i]    [1 * (2+2i) = (2+2i)]    [(1+2i) * (2+2i) = (-2+6i)]
 	NumericTypeFloat                      // [1 * 2 = 2.0]     [1 * 2.5 = 2.5]
 	NumericTypeInt                        // [1 * 2 = 2,]      [1 * 2.5 = 2]
+	NumericTypeGo                         // [7 / 2 = 3]       [7 / 2.0 = 3.5]          [7 / (2+0i) = (3.5+0i)]
+	NumericTypeBig                        // the same as NumericTypeGo but integer is *big.Int and float is *big.Float
+	NumericTypeDecimal                    // [7 / 2 = 3.5]     [0.1 + 0.2 = 0.3]        every number is a base-10 Decimal
+	NumericTypeUint                       // [7 / 2 = 3]       [7 / 2.5 = 3]            [0 - 1 = 18446744073709551615]
 )
 
 type options struct {
-	allowIntegerDividedByZero bool        // true: 2/0 = 0, false: return error
-	numericType               NumericType // treat numeric type as specific type
+	allowIntegerDividedByZero bool             // true: 2/0 = 0, false: return error
+	numericType               NumericType      // treat numeric type as specific type
+	numericTypeSet            bool             // true: numericType is given by WithNumericType
+	resolver                  Resolver         // resolve identifiers into variables' value
+	functions                 map[string]Func  // functions that can be called in expr string
+	constants                 map[string]value // constants resolved after resolver, e.g. pi
+	bigFloatPrec              uint             // *big.Float's precision for NumericTypeBig, 0 means DefaultBigFloatPrecision
+	decimalScale              int              // maximum digits after the decimal point for Decimal's result
+	decimalRounding           RoundingMode     // rounding mode for Decimal's result that exceeds decimalScale
+	overflowPolicy            OverflowPolicy   // what to do when integer operation overflows int64
+	stringCoercion            bool             // true: "n=" + 1 = "n=1", false: return error
+	strictIdents              bool             // true: unknown identifier is an error, false: treat it as string
+	missingAsNil              bool             // true: missing field or index out of range is nil, false: return error
+	nilPropagation            bool             // true: nil + 1 = nil, false: return error
+	losslessConversion        bool             // true: int(3.9) returns error, false: int(3.9) = 3
+	ctx                       context.Context  // abort the evaluation once it is done
+	maxDepth                  int              // maximum nesting depth, 0 means no limit
+	maxNodes                  int              // maximum number of evaluated nodes, 0 means no limit
+	maxStringLen              int              // maximum length of string value, 0 means no limit
+	maxInputLen               int              // maximum length of expr string, 0 means no limit
+	budget                    *budget          // resources used by the current evaluation, only if limited
+	fastParser                bool             // true: parse using expr's own parser, false: parse using go/parser
 }
 
 // Option is Visitor's option.
@@ -51,7 +80,67 @@ func WithAllowIntegerDividedByZero(v bool) Option {
 
 // WithNumericType treats all numeric types as v.
 func WithNumericType(v NumericType) Option {
-	return func(o *options) { o.numericType = v }
+	return func(o *options) { o.numericType, o.numericTypeSet = v, true }
+}
+
+// WithBigFloatPrecision sets the mantissa precision (in bits) of *big.Float used by NumericTypeBig.
+// If v is 0, DefaultBigFloatPrecision will be used.
+func WithBigFloatPrecision(v uint) Option {
+	return func(o *options) { o.bigFloatPrec = v }
+}
+
+// WithDecimalScale sets the maximum number of digits after the decimal point of Decimal's arithmetic result,
+// the result that has more digits will be rounded using the rounding mode, see WithDecimalRounding.
+// Negative v will be treated as 0.
+func WithDecimalScale(v int) Option {
+	return func(o *options) {
+		if v < 0 {
+			v = 0
+		}
+		o.decimalScale = v
+	}
+}
+
+// WithDecimalRounding sets the rounding mode of Decimal's arithmetic result, the default is RoundHalfEven.
+func WithDecimalRounding(v RoundingMode) Option {
+	return func(o *options) { o.decimalRounding = v }
+}
+
+// WithStringCoercion allows "+" to concatenate a string with a number, the number is formatted like fmt.Sprint:
+// "n=" + 1 -> "n=1". Otherwise, only two strings can be concatenated.
+func WithStringCoercion(v bool) Option {
+	return func(o *options) { o.stringCoercion = v }
+}
+
+// WithStrictIdents only allows true, false and identifiers that are resolved (from env, resolver or constants),
+// any other identifier returns an error wrapping ErrUnknownIdentifier instead of being treated as string.
+func WithStrictIdents(v bool) Option {
+	return func(o *options) { o.strictIdents = v }
+}
+
+// WithEnv resolves identifiers in expr string using given env, identifier that is not in env will be treated as usual.
+func WithEnv(env map[string]interface{}) Option {
+	return func(o *options) { o.resolver = Env(env) }
+}
+
+// WithResolver resolves identifiers in expr string using given r, identifier that is not resolved will be treated as usual.
+func WithResolver(r Resolver) Option {
+	return func(o *options) { o.resolver = r }
+}
+
+// WithFunctions registers functions that can be called in expr string, e.g. "tier(qty)".
+// It can be specified multiple times, function with the same name will be replaced.
+func WithFunctions(funcs map[string]Func) Option {
+	return func(o *options) {
+		m := make(map[string]Func, len(o.functions)+len(funcs))
+		for name, fn := range o.functions {
+			m[name] = fn
+		}
+		for name, fn := range funcs {
+			m[name] = fn
+		}
+		o.functions = m
+	}
 }
 
 var _ ast.Visitor = (*Visitor)(nil)
@@ -68,12 +157,14 @@ func defaultOptions() options {
 	return options{
 		allowIntegerDividedByZero: true,
 		numericType:               NumericTypeAuto,
+		decimalScale:              DefaultDecimalScale,
 	}
 }
 
 // NewVisitor create new Visitor. If Option is not specified, these following default options will be set:
 //   - allowIntegerDividedByZero: true
 //   - numericType:               NumericTypeAuto
+//   - decimalScale:              DefaultDecimalScale
 func NewVisitor(opts ...Option) *Visitor {
 	v := &Visitor{
 		options: defaultOptions(),
@@ -102,6 +193,16 @@ func (v *Visitor) Visit(node ast.Node) ast.Visitor {
 	}
 	v.pos = int(node.Pos())
 
+	if v.options.limited() {
+		v.visitLimited(node)
+		return nil
+	}
+	v.visit(node)
+	return nil
+}
+
+// visit visits node based on its type.
+func (v *Visitor) visit(node ast.Node) ast.Visitor {
 	switch d := node.(type) {
 	case *ast.ParenExpr:
 		return v.Visit(d.X)
@@ -113,9 +214,34 @@ func (v *Visitor) Visit(node ast.Node) ast.Visitor {
 		return v.visitBasicLit(d)
 	case *ast.Ident: // handle type: bolean, string without quotation
 		return v.visitIdent(d)
+	case *ast.CallExpr:
+		return v.visitCall(d)
+	case *ast.SelectorExpr:
+		return v.visitSelector(d)
+	case *ast.CompositeLit:
+		return v.visitCompositeLit(d)
+	case *foldedExpr:
+		v.value, v.pos = d.value, d.pos
+		return nil
+	case *identityExpr:
+		v.visitIdentity(d)
+		return nil
+	case *ast.IndexExpr:
+		return v.visitIndex(d)
+	case *ast.SliceExpr:
+		return v.visitSlice(d)
 	}
 
-	return v
+	v.err = newUnsupportedSyntaxError(node)
+	return nil
+}
+
+func newUnsupportedSyntaxError(node ast.Node) error {
+	return &SyntaxError{
+		Msg: conv.DescribeSyntax(node) + " is not supported",
+		Pos: int(node.Pos()),
+		Err: ErrUnsupportedSyntax,
+	}
 }
 
 func (v *Visitor) visitUnary(unaryExpr *ast.UnaryExpr) ast.Visitor {
@@ -130,32 +256,7 @@ func (v *Visitor) visitUnary(unaryExpr *ast.UnaryExpr) ast.Visitor {
 			v.err = vx.err
 			return nil
 		}
-
-		v.value.SetKind(vx.value.Kind())
-		switch unaryExpr.Op {
-		case token.NOT: // negation: !true -> false, !false -> true
-			if vx.value.Kind() != KindBoolean {
-				s := conv.FormatExpr(unaryExpr.X)
-				v.err = &SyntaxError{
-					Msg: "could not do negation: result of \"" + s + "\" is \"" + fmt.Sprintf("%v", vx.value.Any()) + "\" not a boolean",
-					Pos: vx.pos,
-					Err: ErrUnaryOperation,
-				}
-				return nil
-			}
-			v.value = boolValue(!vx.value.Bool())
-		case token.ADD:
-			v.value = vx.value
-		case token.SUB:
-			switch vx.value.Kind() {
-			case KindInt:
-				v.value = int64Value(vx.value.Int64() * -1)
-			case KindFloat:
-				v.value = float64Value(vx.value.Float64() * -1)
-			case KindImag:
-				v.value = complex128Value(vx.value.Complex128() * -1)
-			}
-		}
+		unary(v, vx, unaryExpr)
 	default:
 		v.err = &SyntaxError{
 			Msg: "operator \"" + unaryExpr.Op.String() + "\" is unsupported",
@@ -166,6 +267,62 @@ func (v *Visitor) visitUnary(unaryExpr *ast.UnaryExpr) ast.Visitor {
 	return nil
 }
 
+// unary applies unaryExpr's operator to visitor X's value, the operator must be one of "!", "+" or "-".
+func unary(v, vx *Visitor, unaryExpr *ast.UnaryExpr) {
+	if unaryExpr.Op != token.NOT && vx.value.Kind() == KindNil {
+		if propagateNil(v, vx.value) { // -nil -> nil
+			return
+		}
+		v.err = &SyntaxError{
+			Msg: "could not do unary \"" + unaryExpr.Op.String() + "\": result of \"" + conv.FormatExpr(unaryExpr.X) + "\" is nil",
+			Pos: vx.pos,
+			Err: ErrUnaryOperation,
+		}
+		return
+	}
+
+	v.value.SetKind(vx.value.Kind())
+	switch unaryExpr.Op {
+	case token.NOT: // negation: !true -> false, !false -> true
+		if vx.value.Kind() != KindBoolean {
+			s := conv.FormatExpr(unaryExpr.X)
+			v.err = &SyntaxError{
+				Msg: "could not do negation: result of \"" + s + "\" is \"" + fmt.Sprintf("%v", vx.value.Any()) + "\" not a boolean",
+				Pos: vx.pos,
+				Err: ErrUnaryOperation,
+			}
+			return
+		}
+		v.value = boolValue(!vx.value.Bool())
+	case token.ADD:
+		v.value = vx.value
+	case token.SUB:
+		switch vx.value.Kind() {
+		case KindInt, KindRune:
+			x := vx.value.Int64()
+			setInt64(v, -x, x == math.MinInt64, true, unaryExpr.Op, unaryExpr.OpPos)
+		case KindFloat:
+			v.value = float64Value(vx.value.Float64() * -1)
+		case KindImag:
+			v.value = complex128Value(vx.value.Complex128() * -1)
+		case KindBigInt:
+			v.value = bigIntValue(new(big.Int).Neg(vx.value.BigInt()))
+		case KindBigFloat:
+			v.value = bigFloatValue(new(big.Float).Neg(vx.value.BigFloat()))
+		case KindUint:
+			x := vx.value.Uint64()
+			if v.options.numericType == NumericTypeUint {
+				setUint64(v, -x, x != 0, false, unaryExpr.Op, unaryExpr.OpPos)
+				return
+			}
+			setMixedInt(v, x != 0, x, false, unaryExpr.Op, unaryExpr.OpPos) // "-9223372036854775808" is MinInt64.
+		case KindDecimal:
+			d := vx.value.Decimal()
+			v.value = decimalValue(Decimal{unscaled: new(big.Int).Neg(d.int()), scale: d.scale})
+		}
+	}
+}
+
 func (v *Visitor) visitBinary(binaryExpr *ast.BinaryExpr) ast.Visitor {
 	vx := pool.Get().(*Visitor)
 	defer pool.Put(vx)
@@ -177,6 +334,10 @@ func (v *Visitor) visitBinary(binaryExpr *ast.BinaryExpr) ast.Visitor {
 		return nil
 	}
 
+	if shortCircuit(v, vx, binaryExpr) {
+		return nil
+	}
+
 	vy := pool.Get().(*Visitor)
 	defer pool.Put(vy)
 	vy.reset(v.options)
@@ -187,6 +348,22 @@ func (v *Visitor) visitBinary(binaryExpr *ast.BinaryExpr) ast.Visitor {
 		return nil
 	}
 
+	binary(v, vx, vy, binaryExpr)
+	return nil
+}
+
+// binary applies binaryExpr's operator to visitor X and visitor Y values.
+func binary(v, vx, vy *Visitor, binaryExpr *ast.BinaryExpr) {
+	vx.value, vy.value = runeAsInt(vx.value), runeAsInt(vy.value) // 'a' + 1 -> 98
+
+	switch binaryExpr.Op {
+	case token.ADD, token.SUB, token.MUL, token.QUO, token.REM,
+		token.AND, token.OR, token.XOR, token.AND_NOT, token.SHL, token.SHR:
+		if propagateNil(v, vx.value, vy.value) { // nil + 1 -> nil
+			return
+		}
+	}
+
 	switch binaryExpr.Op {
 	case token.EQL, token.NEQ, token.GTR, token.GEQ, token.LSS, token.LEQ:
 		comparison(v, vx, vy, binaryExpr)
@@ -197,29 +374,176 @@ func (v *Visitor) visitBinary(binaryExpr *ast.BinaryExpr) ast.Visitor {
 	case token.LAND, token.LOR:
 		logical(v, vx, vy, binaryExpr)
 	}
+}
+
+func (v *Visitor) visitCall(callExpr *ast.CallExpr) ast.Visitor {
+	if b, ok := lookupBuiltin(callExpr, &v.options); ok {
+		if v.err = validateArgs(callExpr, conv.FormatExpr(callExpr.Fun), b.minArgs, b.maxArgs); v.err != nil {
+			return nil
+		}
+		b.fn(v, callExpr)
+		return nil
+	}
+
+	fn, err := lookupFunc(callExpr, &v.options)
+	if err != nil {
+		v.err = err
+		return nil
+	}
+
+	args := make([]*Visitor, len(callExpr.Args))
+	for i := range callExpr.Args {
+		va := pool.Get().(*Visitor)
+		defer pool.Put(va)
+		va.reset(v.options)
+
+		va.Visit(callExpr.Args[i])
+		if va.err != nil {
+			v.err = va.err
+			return nil
+		}
+		args[i] = va
+	}
+
+	call(v, fn, args, callExpr)
 	return nil
 }
 
 func (v *Visitor) visitBasicLit(basicLit *ast.BasicLit) ast.Visitor {
+	if v.options.numericType == NumericTypeBig {
+		switch basicLit.Kind {
+		case token.INT:
+			val, ok := new(big.Int).SetString(basicLit.Value, 0)
+			if !ok {
+				v.err = newBasicLitError(basicLit, strconv.ErrSyntax)
+				return nil
+			}
+			v.value = bigIntValue(val)
+			return nil
+		case token.FLOAT:
+			val, err := parseBigFloatLiteral(basicLit.Value, v.options.bigFloatPrecision())
+			if err != nil {
+				v.err = newBasicLitError(basicLit, err)
+				return nil
+			}
+			v.value = bigFloatValue(val)
+			return nil
+		}
+	}
+
+	if v.options.numericType == NumericTypeDecimal && (basicLit.Kind == token.INT || basicLit.Kind == token.FLOAT) {
+		val, err := parseDecimalLiteral(basicLit.Value)
+		if err != nil {
+			v.err = newBasicLitError(basicLit, err)
+			return nil
+		}
+		v.value = decimalValue(val)
+		return nil
+	}
+
 	switch basicLit.Kind {
 	case token.INT:
-		val, _ := strconv.ParseInt(basicLit.Value, 0, 64)
+		if v.options.numericType == NumericTypeUint {
+			val, err := strconv.ParseUint(basicLit.Value, 0, 64)
+			if err != nil {
+				v.err = newBasicLitError(basicLit, err)
+				return nil
+			}
+			v.value = uint64Value(val)
+			break
+		}
+		val, err := strconv.ParseInt(basicLit.Value, 0, 64)
+		if err != nil {
+			u, uerr := strconv.ParseUint(basicLit.Value, 0, 64) // > MaxInt64 but it fits into uint64
+			if uerr != nil {
+				v.err = newBasicLitError(basicLit, err)
+				return nil
+			}
+			v.value = uint64Value(u)
+			break
+		}
 		v.value = int64Value(val)
 	case token.FLOAT:
-		val, _ := strconv.ParseFloat(basicLit.Value, 64)
+		val, err := strconv.ParseFloat(basicLit.Value, 64)
+		if err != nil { // out of range, e.g. "1e400"
+			v.err = newBasicLitError(basicLit, err)
+			return nil
+		}
 		v.value = float64Value(val)
 	case token.IMAG:
-		val, _ := strconv.ParseComplex(basicLit.Value, 128)
+		val, err := strconv.ParseComplex(basicLit.Value, 128)
+		if errors.Is(err, strconv.ErrRange) {
+			v.err = newBasicLitError(basicLit, err)
+			return nil
+		}
 		v.value = complex128Value(val)
 	case token.CHAR:
-		fallthrough // treat as string
+		s, err := strconv.Unquote(basicLit.Value)
+		if err != nil {
+			v.err = newBasicLitError(basicLit, err)
+			return nil
+		}
+		r, _ := utf8.DecodeRuneInString(s)
+		v.value = runeValue(r)
 	case token.STRING:
-		v.value = stringValue(strings.TrimFunc(basicLit.Value, func(r rune) bool { return r == '\'' || r == '`' || r == '"' }))
+		s, err := strconv.Unquote(basicLit.Value)
+		if err != nil {
+			v.err = newBasicLitError(basicLit, err)
+			return nil
+		}
+		v.value = stringValue(s)
 	}
 	return nil
 }
 
+func newBasicLitError(basicLit *ast.BasicLit, err error) error {
+	return &SyntaxError{
+		Msg: "could not parse " + strings.ToLower(basicLit.Kind.String()) + " literal \"" + basicLit.Value + "\"",
+		Pos: int(basicLit.ValuePos),
+		Err: err,
+	}
+}
+
 func (v *Visitor) visitIdent(indent *ast.Ident) ast.Visitor {
+	if v.options.resolver != nil {
+		if x, ok := v.options.resolver.Resolve(indent.Name); ok {
+			if v.value, ok = valueOf(x); !ok {
+				v.err = &SyntaxError{
+					Msg: fmt.Sprintf("variable %q has unsupported type %T", indent.Name, x),
+					Pos: int(indent.NamePos),
+					Err: ErrUnsupportedVariableType,
+				}
+			}
+			return nil
+		}
+	}
+
+	if val, ok := v.options.constants[indent.Name]; ok {
+		v.value = val
+		return nil
+	}
+
+	if indent.Name == "nil" {
+		v.value = nilValue()
+		return nil
+	}
+
+	if v.options.strictIdents {
+		switch indent.Name {
+		case "true":
+			v.value = boolValue(true)
+		case "false":
+			v.value = boolValue(false)
+		default:
+			v.err = &SyntaxError{
+				Msg: fmt.Sprintf("unknown identifier %q", indent.Name),
+				Pos: int(indent.NamePos),
+				Err: ErrUnknownIdentifier,
+			}
+		}
+		return nil
+	}
+
 	vb, err := strconv.ParseBool(indent.String())
 	if err != nil {
 		v.value = stringValue(indent.String()) // treat as string
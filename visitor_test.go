@@ -18,6 +18,8 @@ import (
 	"fmt"
 	"go/ast"
 	"go/parser"
+	"go/token"
+	"strconv"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -38,10 +40,23 @@ func TestOptions(t *testing.T) {
 			opts: []Option{
 				WithAllowIntegerDividedByZero(true),
 				WithNumericType(NumericTypeInt),
+				WithEnv(map[string]interface{}{"a": 1}),
+				WithDecimalScale(2),
+				WithDecimalRounding(RoundHalfUp),
+				WithStringCoercion(true),
+				WithStrictIdents(true),
+				WithMissingAsNil(true),
 			},
 			options: options{
 				allowIntegerDividedByZero: true,
 				numericType:               NumericTypeInt,
+				numericTypeSet:            true,
+				resolver:                  Env{"a": 1},
+				decimalScale:              2,
+				decimalRounding:           RoundHalfUp,
+				stringCoercion:            true,
+				strictIdents:              true,
+				missingAsNil:              true,
 			},
 		},
 	}
@@ -116,9 +131,9 @@ func TestVisit(t *testing.T) {
 			expectedKind:  KindBoolean,
 		},
 		{
-			in:           "true || !(!(10 * 100 %2))",
-			expectedKind: KindIllegal,
-			expectedErr:  ErrUnaryOperation,
+			in:            "true || !(!(10 * 100 %2))", // short-circuit: Y is not evaluated
+			expectedValue: boolValue(true),
+			expectedKind:  KindBoolean,
 		},
 		{
 			in:           "!(!(10 * 100 %2)) || true ",
@@ -156,16 +171,18 @@ func TestVisit(t *testing.T) {
 	}
 
 	tt2 := []struct {
-		in       ast.Expr
-		expected *Visitor
+		in          ast.Expr
+		expected    *Visitor
+		expectedErr error
 	}{
 		{
 			in:       nil,
 			expected: &Visitor{},
 		},
 		{
-			in:       &ast.BadExpr{},
-			expected: &Visitor{},
+			in:          &ast.BadExpr{},
+			expected:    &Visitor{},
+			expectedErr: ErrUnsupportedSyntax,
 		},
 	}
 
@@ -174,8 +191,8 @@ func TestVisit(t *testing.T) {
 		t.Run("", func(t *testing.T) {
 			v := &Visitor{}
 			ast.Walk(v, tc.in)
-			if v.Err() != tc.expected.Err() {
-				t.Fatalf("expected err: %v, got: %v", tc.expected.Err(), v.Err())
+			if !errors.Is(v.Err(), tc.expectedErr) {
+				t.Fatalf("expected err: %v, got: %v", tc.expectedErr, v.Err())
 			}
 			if v.Kind() != tc.expected.Kind() {
 				t.Fatalf("expected kind: %s, got: %s", tc.expected.Kind(), v.Kind())
@@ -189,12 +206,21 @@ func TestVisit(t *testing.T) {
 
 func TestKindString(t *testing.T) {
 	kinds := [...]string{
-		KindIllegal: "KindIllegal",
-		KindBoolean: "KindBoolean",
-		KindInt:     "KindInt",
-		KindFloat:   "KindFloat",
-		KindImag:    "KindImag",
-		KindString:  "KindString",
+		KindIllegal:  "KindIllegal",
+		KindBoolean:  "KindBoolean",
+		KindInt:      "KindInt",
+		KindFloat:    "KindFloat",
+		KindImag:     "KindImag",
+		KindBigInt:   "KindBigInt",
+		KindBigFloat: "KindBigFloat",
+		KindDecimal:  "KindDecimal",
+		KindUint:     "KindUint",
+		KindRune:     "KindRune",
+		KindString:   "KindString",
+		KindNil:      "KindNil",
+		KindObject:   "KindObject",
+		KindList:     "KindList",
+		KindAny:      "KindAny",
 	}
 
 	for kind, expected := range kinds {
@@ -224,3 +250,156 @@ func TestKindString(t *testing.T) {
 		})
 	}
 }
+
+func TestVisitInvalidBasicLit(t *testing.T) {
+	// go/parser reports invalid literals but still returns them in the AST, the visitor must not accept them.
+	tt := []*ast.BasicLit{
+		{ValuePos: 3, Kind: token.STRING, Value: `"a\qb"`},
+		{ValuePos: 3, Kind: token.STRING, Value: `"\xZZ"`},
+		{ValuePos: 3, Kind: token.STRING, Value: `"unterminated`},
+		{ValuePos: 3, Kind: token.CHAR, Value: `'ab'`},
+		{ValuePos: 3, Kind: token.CHAR, Value: `'\400'`},
+	}
+
+	for _, basicLit := range tt {
+		basicLit := basicLit
+		t.Run(basicLit.Value, func(t *testing.T) {
+			v := NewVisitor()
+			ast.Walk(v, basicLit)
+
+			var syntaxErr *SyntaxError
+			if !errors.As(v.Err(), &syntaxErr) || !errors.Is(syntaxErr, strconv.ErrSyntax) {
+				t.Fatalf("expected err: %v, got: %v", strconv.ErrSyntax, v.Err())
+			}
+			if syntaxErr.Pos != 3 {
+				t.Fatalf("expected pos: 3, got: %d", syntaxErr.Pos)
+			}
+		})
+	}
+}
+
+func TestVisitOutOfRangeBasicLit(t *testing.T) {
+	tt := []*ast.BasicLit{
+		{ValuePos: 3, Kind: token.INT, Value: "99999999999999999999"},
+		{ValuePos: 3, Kind: token.FLOAT, Value: "1e400"},
+		{ValuePos: 3, Kind: token.FLOAT, Value: "0x1p1024"},
+		{ValuePos: 3, Kind: token.IMAG, Value: "1e400i"},
+	}
+
+	for _, basicLit := range tt {
+		basicLit := basicLit
+		t.Run(basicLit.Value, func(t *testing.T) {
+			v := NewVisitor()
+			ast.Walk(v, basicLit)
+
+			var syntaxErr *SyntaxError
+			if !errors.As(v.Err(), &syntaxErr) || !errors.Is(syntaxErr, strconv.ErrRange) {
+				t.Fatalf("expected err: %v, got: %v", strconv.ErrRange, v.Err())
+			}
+			if syntaxErr.Pos != 3 {
+				t.Fatalf("expected pos: 3, got: %d", syntaxErr.Pos)
+			}
+		})
+	}
+}
+
+func TestStrictIdents(t *testing.T) {
+	env := map[string]interface{}{"status": "active", "T": 1}
+
+	tt := []struct {
+		in          string
+		expected    string
+		expectedErr error
+		expectedPos int
+	}{
+		{in: "true && !false", expected: "true"},
+		{in: "status == \"active\"", expected: "true"},
+		{in: "T + 1", expected: "2"}, // resolved from env
+		{in: "pi > 3", expected: "true"},
+		{in: "abs(-1)", expected: "1"},
+		{in: "statsu == \"active\"", expectedErr: ErrUnknownIdentifier, expectedPos: 1},
+		{in: "status == active", expectedErr: ErrUnknownIdentifier, expectedPos: 11},
+		{in: "True", expectedErr: ErrUnknownIdentifier, expectedPos: 1},
+		{in: "F || true", expectedErr: ErrUnknownIdentifier, expectedPos: 1},
+		{in: "abs(x)", expectedErr: ErrUnknownIdentifier, expectedPos: 5},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.in, func(t *testing.T) {
+			e, err := parser.ParseExpr(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := NewVisitor(WithStrictIdents(true), WithEnv(env), WithMath())
+			ast.Walk(v, e)
+
+			var syntaxErr *SyntaxError
+			if tc.expectedErr != nil {
+				if !errors.As(v.Err(), &syntaxErr) || !errors.Is(syntaxErr, tc.expectedErr) {
+					t.Fatalf("expected err: %v, got: %v", tc.expectedErr, v.Err())
+				}
+				if syntaxErr.Pos != tc.expectedPos {
+					t.Fatalf("expected pos: %d, got: %d", tc.expectedPos, syntaxErr.Pos)
+				}
+				return
+			}
+			if err := v.Err(); err != nil {
+				t.Fatalf("expected nil error, got: %v", err)
+			}
+			if v.Value() != tc.expected {
+				t.Fatalf("expected value: %s, got: %s", tc.expected, v.Value())
+			}
+		})
+	}
+
+	// non-strict mode keeps treating unknown identifier as string.
+	v := NewVisitor(WithEnv(env))
+	ast.Walk(v, &ast.Ident{Name: "statsu"})
+	if v.Err() != nil || v.Value() != "statsu" {
+		t.Fatalf("expected value: statsu, got: %s (err: %v)", v.Value(), v.Err())
+	}
+}
+
+func TestUnsupportedSyntax(t *testing.T) {
+	tt := []struct {
+		in          string
+		expectedMsg string
+		expectedPos int
+	}{
+		{in: "*p", expectedMsg: "pointer indirection \"*p\" is not supported", expectedPos: 1},
+		{in: "1 + struct{}{}", expectedMsg: "composite literal is not supported", expectedPos: 5},
+		{in: "map[string]int{\"a\": 1}", expectedMsg: "composite literal is not supported", expectedPos: 1},
+		{in: "func() int { return 1 }", expectedMsg: "function literal is not supported", expectedPos: 1},
+		{in: "x.(int) == 1", expectedMsg: "type assertion is not supported", expectedPos: 1},
+		{in: "!*p", expectedMsg: "pointer indirection \"*p\" is not supported", expectedPos: 2},
+		{in: "-[]int", expectedMsg: "type expression \"[]int\" is not supported", expectedPos: 2},
+		{in: "1 < map[string]int", expectedMsg: "type expression is not supported", expectedPos: 5},
+		{in: "(chan int)", expectedMsg: "type expression is not supported", expectedPos: 2},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.in, func(t *testing.T) {
+			e, err := parser.ParseExpr(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := NewVisitor()
+			ast.Walk(v, e)
+
+			var syntaxErr *SyntaxError
+			if !errors.As(v.Err(), &syntaxErr) || !errors.Is(syntaxErr, ErrUnsupportedSyntax) {
+				t.Fatalf("expected err: %v, got: %v", ErrUnsupportedSyntax, v.Err())
+			}
+			if syntaxErr.Msg != tc.expectedMsg {
+				t.Fatalf("expected msg: %s, got: %s", tc.expectedMsg, syntaxErr.Msg)
+			}
+			if syntaxErr.Pos != tc.expectedPos {
+				t.Fatalf("expected pos: %d, got: %d", tc.expectedPos, syntaxErr.Pos)
+			}
+		})
+	}
+}
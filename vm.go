@@ -0,0 +1,205 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"go/ast"
+	"go/token"
+	"sync"
+)
+
+// opcode is the operation of an instruction.
+type opcode byte
+
+const (
+	opConst        opcode = iota // push constants[arg].
+	opIdent                      // push the resolved identifier nodes[arg].
+	opVisit                      // push the result of nodes[arg] that is evaluated by Visitor.
+	opUnary                      // pop x, push the result of unary nodes[arg] applied to x.
+	opBinary                     // pop y and x, push the result of binary nodes[arg] applied to x and y.
+	opShortCircuit               // if the result of logical nodes[arg] can be determined by x alone, replace x with it and jump to jump.
+)
+
+// instruction is a single instruction of bytecode.
+type instruction struct {
+	op         opcode
+	arg        int // index of constants or nodes.
+	jump       int // the instruction to jump to, only for opShortCircuit.
+	posX, posY int // the evaluated position of the operands, used to create the same error as Visitor's.
+}
+
+// bytecode is an expression that has been lowered into instructions, so it can be evaluated by a stack machine
+// without walking the tree. Any expression that is not lowered is evaluated by Visitor using opVisit, so the result
+// (including the error) is always the same as evaluating the expression using Visitor.
+type bytecode struct {
+	code      []instruction
+	constants []value
+	nodes     []ast.Expr
+	maxStack  int
+}
+
+// compileBytecode lowers e into bytecode.
+func compileBytecode(e ast.Expr) *bytecode {
+	bc := &bytecode{}
+	bc.emit(e, 0)
+	return bc
+}
+
+// emit appends the instructions of e, depth is the stack size before e is evaluated.
+func (bc *bytecode) emit(e ast.Expr, depth int) {
+	if depth+1 > bc.maxStack {
+		bc.maxStack = depth + 1
+	}
+
+	switch d := e.(type) {
+	case *ast.ParenExpr:
+		bc.emit(d.X, depth)
+	case *foldedExpr:
+		bc.constants = append(bc.constants, d.value)
+		bc.code = append(bc.code, instruction{op: opConst, arg: len(bc.constants) - 1})
+	case *ast.Ident:
+		bc.code = append(bc.code, instruction{op: opIdent, arg: bc.node(d)})
+	case *ast.UnaryExpr:
+		switch d.Op {
+		case token.NOT, token.ADD, token.SUB:
+			bc.emit(d.X, depth)
+			bc.code = append(bc.code, instruction{op: opUnary, arg: bc.node(d), posX: evalPos(d.X)})
+		default:
+			bc.code = append(bc.code, instruction{op: opVisit, arg: bc.node(d)})
+		}
+	case *ast.BinaryExpr:
+		arg := bc.node(d)
+		bc.emit(d.X, depth)
+		shortCircuit := -1
+		if d.Op == token.LAND || d.Op == token.LOR {
+			shortCircuit = len(bc.code)
+			bc.code = append(bc.code, instruction{op: opShortCircuit, arg: arg, posX: evalPos(d.X)})
+		}
+		bc.emit(d.Y, depth+1)
+		bc.code = append(bc.code, instruction{op: opBinary, arg: arg, posX: evalPos(d.X), posY: evalPos(d.Y)})
+		if shortCircuit != -1 {
+			bc.code[shortCircuit].jump = len(bc.code)
+		}
+	default:
+		bc.code = append(bc.code, instruction{op: opVisit, arg: bc.node(d)})
+	}
+}
+
+// node appends e into nodes and returns its index.
+func (bc *bytecode) node(e ast.Expr) int {
+	bc.nodes = append(bc.nodes, e)
+	return len(bc.nodes) - 1
+}
+
+// evalPos returns the position of e that is set by Visitor after evaluating e.
+func evalPos(e ast.Expr) int {
+	for {
+		switch d := e.(type) {
+		case *ast.ParenExpr:
+			e = d.X
+			continue
+		case *foldedExpr:
+			return d.pos
+		}
+		return int(e.Pos())
+	}
+}
+
+// machine is a stack machine that runs bytecode, it is reused across evaluations to avoid allocations.
+type machine struct {
+	stack     []value
+	v, vx, vy Visitor // holds the operands and the result of an operator.
+}
+
+var machines = sync.Pool{New: func() interface{} { return new(machine) }}
+
+// run evaluates bc using the given options.
+func (bc *bytecode) run(o options) (value, error) {
+	m := machines.Get().(*machine)
+	defer machines.Put(m)
+
+	if cap(m.stack) < bc.maxStack {
+		m.stack = make([]value, 0, bc.maxStack)
+	}
+	m.v.reset(o)
+	m.vx.reset(o)
+	m.vy.reset(o)
+
+	val, err := m.run(bc, o)
+
+	for i := range m.stack { // release the references.
+		m.stack[i] = value{}
+	}
+	m.stack = m.stack[:0]
+	m.v.reset(options{})
+	m.vx.reset(options{})
+	m.vy.reset(options{})
+
+	return val, err
+}
+
+func (m *machine) run(bc *bytecode, o options) (value, error) {
+	for pc := 0; pc < len(bc.code); pc++ {
+		ins := &bc.code[pc]
+		switch ins.op {
+		case opConst:
+			m.stack = append(m.stack, bc.constants[ins.arg])
+		case opIdent:
+			m.v.value, m.v.err = value{}, nil
+			m.v.visitIdent(bc.nodes[ins.arg].(*ast.Ident))
+			if m.v.err != nil {
+				return value{}, m.v.err
+			}
+			m.stack = append(m.stack, m.v.value)
+		case opVisit:
+			val, err := visit(bc.nodes[ins.arg], o)
+			if err != nil {
+				return value{}, err
+			}
+			m.stack = append(m.stack, val)
+		case opUnary:
+			top := len(m.stack) - 1
+			m.vx.value, m.vx.pos = m.stack[top], ins.posX
+			m.v.value, m.v.err = value{}, nil
+			unary(&m.v, &m.vx, bc.nodes[ins.arg].(*ast.UnaryExpr))
+			if m.v.err != nil {
+				return value{}, m.v.err
+			}
+			m.stack[top] = m.v.value
+		case opBinary:
+			top := len(m.stack) - 1
+			m.vx.value, m.vx.pos = m.stack[top-1], ins.posX
+			m.vy.value, m.vy.pos = m.stack[top], ins.posY
+			m.v.value, m.v.err = value{}, nil
+			binary(&m.v, &m.vx, &m.vy, bc.nodes[ins.arg].(*ast.BinaryExpr))
+			if m.v.err != nil {
+				return value{}, m.v.err
+			}
+			m.stack = m.stack[:top]
+			m.stack[top-1] = m.v.value
+		case opShortCircuit:
+			top := len(m.stack) - 1
+			m.vx.value, m.vx.pos = m.stack[top], ins.posX
+			m.v.value, m.v.err = value{}, nil
+			if shortCircuit(&m.v, &m.vx, bc.nodes[ins.arg].(*ast.BinaryExpr)) {
+				if m.v.err != nil {
+					return value{}, m.v.err
+				}
+				m.stack[top] = m.v.value
+				pc = ins.jump - 1
+			}
+		}
+	}
+	return m.stack[0], nil
+}
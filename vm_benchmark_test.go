@@ -0,0 +1,73 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import "testing"
+
+// BenchmarkBytecode compares the evaluation of a compiled Program using Visitor (tree-walking) and bytecode.
+func BenchmarkBytecode(b *testing.B) {
+	tt := []struct {
+		name string
+		in   string
+		env  Env
+	}{
+		{
+			name: "Boolean",
+			in:   "((qty + 2 == 5) && (qty * 2 <= 10)) || (!(qty + 1 == 5) && (2 / qty < 5)) || (qty % 4 == 1)",
+			env:  Env{"qty": 3.0},
+		},
+		{
+			name: "Int",
+			in:   "((qty + 2 * 5) / (7 - 3)) + ((15 - 2 * qty) * 2)",
+			env:  Env{"qty": int64(10)},
+		},
+		{
+			name: "Float",
+			in:   "(((qty + 5) * (7 - 3)) / (2 + 1) + (6 * (4 - price)) - 5) * 2 + (8 / price)",
+			env:  Env{"qty": 12.0, "price": 2.2},
+		},
+		{
+			name: "Env",
+			in:   "price - (price * discountPercentage)",
+			env:  Env{"price": 10.0, "discountPercentage": 0.15},
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		p, err := Compile(tc.in)
+		if err != nil {
+			b.Fatalf("expected nil, got: %v", err)
+		}
+		o := p.options
+		o.resolver = tc.env
+
+		b.Run(tc.name+"/Visitor", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := visit(p.expr, o); err != nil {
+					b.Fatalf("expected nil, got: %v", err)
+				}
+			}
+		})
+		b.Run(tc.name+"/Bytecode", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := p.code.run(o); err != nil {
+					b.Fatalf("expected nil, got: %v", err)
+				}
+			}
+		})
+	}
+}
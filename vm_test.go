@@ -0,0 +1,123 @@
+// Copyright 2023 The Expr Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestBytecode(t *testing.T) {
+	env := map[string]interface{}{
+		"x":     2.5,
+		"i":     int64(3),
+		"u":     uint64(3),
+		"r":     'a',
+		"s":     "a",
+		"ok":    true,
+		"n":     nil,
+		"max":   int64(math.MaxInt64),
+		"items": []int{1, 2, 3},
+		"order": map[string]interface{}{"qty": 2, "coupon": nil},
+	}
+
+	ins := []string{
+		"x", "i", "undefined", "-x", "+i", "!ok", "!x", "-s", "-n", "-(-i)",
+		"x + i * 2 - u / 3", "(x + i) * (2 - u) / 3", "i % 2", "x % 2", "i / 0", "x / 0", "max + i", "-max - i - i",
+		"i << 2", "i & 1 | 2 ^ u", "u &^ 1", "i >> x",
+		"x > i", "x == 2.5", "s == \"a\"", "s < \"b\"", "n == nil", "n != x", "n < 1", "r == 'a'", "r + 1",
+		"s + \"b\"", "s + x", "s * 2", "n + 1", "x + n * 2",
+		"ok && x > 1", "ok || x", "!ok && x", "!ok || x", "x && ok", "ok && (x || ok)", "(i > 1 && ok) || s",
+		"true && ok", "ok && true", "false || ok", "true && x", "x * 1", "1 * s", "i + 0",
+		"items[0] + x", "items[9]", "order.qty * x", "order.coupon.code", "order.missing + 1", "coalesce(n, x) + 1",
+		"iif(ok, x, s) + 1", "iif(x, 1, 2)", "in(x, []any{1, 2.5})", "int(x) + 1", "string(i) + s", "\"abc\"[1:] + s",
+		"((1 + 2) * x) - (3 / (4 - i))", "(1 + 2) + s", "((1)) + s", "2 * (x + (i * (u - (r - 97))))",
+	}
+
+	numericTypes := []NumericType{
+		NumericTypeAuto, NumericTypeGo, NumericTypeComplex, NumericTypeFloat, NumericTypeInt, NumericTypeUint,
+		NumericTypeBig, NumericTypeDecimal,
+	}
+
+	optionSets := [][]Option{
+		nil,
+		{WithMissingAsNil(true), WithNilPropagation(true)},
+		{WithStringCoercion(true), WithAllowIntegerDividedByZero(false), WithOverflowPolicy(OverflowError)},
+		{WithStrictIdents(true)},
+	}
+
+	for _, in := range ins {
+		for _, numericType := range numericTypes {
+			for i, opts := range optionSets {
+				in, numericType, opts := in, numericType, opts
+				t.Run(fmt.Sprintf("%d: [%d] %s", numericType, i, in), func(t *testing.T) {
+					opts = append([]Option{WithEnv(env), WithNumericType(numericType)}, opts...)
+					p, err := Compile(in, opts...)
+					if err != nil {
+						t.Skipf("compile: %v", err)
+					}
+
+					expectedVal, expectedErr := visit(p.expr, p.options)
+					val, err := p.code.run(p.options)
+
+					if fmt.Sprint(err) != fmt.Sprint(expectedErr) {
+						t.Fatalf("expected err: %v, got: %v", expectedErr, err)
+					}
+					if err != nil {
+						return
+					}
+					if val.Kind() != expectedVal.Kind() {
+						t.Fatalf("expected kind: %s, got: %s", expectedVal.Kind(), val.Kind())
+					}
+					if s, expected := fmt.Sprint(val.Any()), fmt.Sprint(expectedVal.Any()); s != expected {
+						t.Fatalf("expected: %s, got: %s", expected, s)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestBytecodeAllocs(t *testing.T) {
+	tt := []struct {
+		in  string
+		env Env
+	}{
+		{in: "price - (price * discount)", env: Env{"price": 10.0, "discount": 0.15}},
+		{in: "qty * 2 > limit && !blocked || vip", env: Env{"qty": int64(6), "limit": 10.0, "blocked": false, "vip": true}},
+		{in: "((10 + 2 * 5) / (7 - 3)) + ((15 - 2 * 3) * 2) + x", env: Env{"x": int64(1)}},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.in, func(t *testing.T) {
+			p, err := Compile(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p.options.resolver = tc.env
+			if _, err := p.code.run(p.options); err != nil {
+				t.Fatal(err)
+			}
+
+			allocs := testing.AllocsPerRun(100, func() {
+				_, _ = p.code.run(p.options)
+			})
+			if allocs != 0 {
+				t.Fatalf("expected zero allocation, got: %v", allocs)
+			}
+		})
+	}
+}